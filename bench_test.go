@@ -2,6 +2,7 @@ package cmdline
 
 import (
 	"flag"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -35,3 +36,34 @@ func BenchmarkFlagSet_Parse(b *testing.B) {
 		_ = d
 	}
 }
+
+// largeArgs returns n options -o0 ... -o<n-1>, each with a value,
+// followed by n arguments.
+func largeArgs(n int) []string {
+	args := []string{"cmd"}
+	for i := 0; i < n; i++ {
+		args = append(args, fmt.Sprintf("-o%v", i), "value")
+	}
+	for i := 0; i < n; i++ {
+		args = append(args, fmt.Sprintf("arg%v", i))
+	}
+	return args
+}
+
+func BenchmarkParse_large(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		sh := clitest.NewShellT(largeArgs(n)...)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				cli := NewParser()
+				cli.SetShell(sh)
+				for j := 0; j < n; j++ {
+					_ = cli.Option(fmt.Sprintf("-o%v", j)).String("")
+				}
+				_ = cli.NamedArg("ARGS...").Strings()
+				_ = cli.Error()
+			}
+		})
+		sh.Cleanup()
+	}
+}
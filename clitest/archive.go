@@ -0,0 +1,84 @@
+package clitest
+
+import (
+	"strings"
+)
+
+// parseArchive parses the txtar format, ie. a comment followed by
+// files each starting with a marker line
+//
+//	-- name --
+func parseArchive(data string) *archive {
+	a := &archive{}
+	var f *archiveFile
+	for _, line := range strings.SplitAfter(data, "\n") {
+		name, isMarker := fileMarker(line)
+		switch {
+		case isMarker:
+			a.files = append(a.files, &archiveFile{name: name})
+			f = a.files[len(a.files)-1]
+		case f != nil:
+			f.data += line
+		default:
+			a.comment += line
+		}
+	}
+	return a
+}
+
+// fileMarker returns the name of a file marker line.
+func fileMarker(line string) (string, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "-- ") || !strings.HasSuffix(line, " --") {
+		return "", false
+	}
+	name := strings.TrimSpace(line[3 : len(line)-3])
+	return name, name != ""
+}
+
+type archive struct {
+	comment string
+	files   []*archiveFile
+}
+
+type archiveFile struct {
+	name string
+	data string
+}
+
+// get returns the data of the named file.
+func (a *archive) get(name string) (string, bool) {
+	for _, f := range a.files {
+		if f.name == name {
+			return f.data, true
+		}
+	}
+	return "", false
+}
+
+// set sets or adds the named file, returns true if changed.
+func (a *archive) set(name, data string) bool {
+	for _, f := range a.files {
+		if f.name == name {
+			changed := f.data != data
+			f.data = data
+			return changed
+		}
+	}
+	a.files = append(a.files, &archiveFile{name: name, data: data})
+	return true
+}
+
+// String returns the archive in txtar format.
+func (a *archive) String() string {
+	var b strings.Builder
+	b.WriteString(a.comment)
+	for _, f := range a.files {
+		b.WriteString("-- " + f.name + " --\n")
+		b.WriteString(f.data)
+		if f.data != "" && !strings.HasSuffix(f.data, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
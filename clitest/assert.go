@@ -0,0 +1,174 @@
+package clitest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gregoryv/golden"
+)
+
+// Assert returns assertions on the result, failures include the
+// result dump.
+func (r *Result) Assert(t testing.TB) *Asserter {
+	return &Asserter{t: t, r: r}
+}
+
+// Assert returns assertions on the current state of the shell, e.g.
+// after calling a command directly without Run.
+func (s *ShellT) Assert(t testing.TB) *Asserter {
+	r := &Result{
+		Shell:    s,
+		ExitCode: s.ExitCode,
+		Stdout:   s.Out.String(),
+		Stderr:   s.Err.String(),
+	}
+	return r.Assert(t)
+}
+
+// Asserter checks a result, each method returns the asserter so
+// checks can be chained, e.g.
+//
+//	clitest.Run(t, main, "mycmd", "-h").Assert(t).
+//		ExitCode(0).StdoutContains("Usage:").NoStderr()
+type Asserter struct {
+	t testing.TB
+	r *Result
+}
+
+// ExitCode checks the exit code equals exp.
+func (a *Asserter) ExitCode(exp int) *Asserter {
+	a.t.Helper()
+	if a.r.ExitCode != exp {
+		a.fail("got exit %v, expected %v", a.r.ExitCode, exp)
+	}
+	return a
+}
+
+// StdoutContains checks stdout contains each of exp.
+func (a *Asserter) StdoutContains(exp ...string) *Asserter {
+	a.t.Helper()
+	a.contains("stdout", a.r.Stdout, exp)
+	return a
+}
+
+// StderrContains checks stderr contains each of exp.
+func (a *Asserter) StderrContains(exp ...string) *Asserter {
+	a.t.Helper()
+	a.contains("stderr", a.r.Stderr, exp)
+	return a
+}
+
+// StdoutMatches checks stdout matches the regular expression, in
+// multiline mode.
+func (a *Asserter) StdoutMatches(expr string) *Asserter {
+	a.t.Helper()
+	a.matches("stdout", a.r.Stdout, expr)
+	return a
+}
+
+// StderrMatches checks stderr matches the regular expression, in
+// multiline mode.
+func (a *Asserter) StderrMatches(expr string) *Asserter {
+	a.t.Helper()
+	a.matches("stderr", a.r.Stderr, expr)
+	return a
+}
+
+// NoStderr checks nothing is written to stderr.
+func (a *Asserter) NoStderr() *Asserter {
+	a.t.Helper()
+	if a.r.Stderr != "" {
+		a.fail("unexpected stderr")
+	}
+	return a
+}
+
+// StdoutGolden checks stdout equals the content of the golden file.
+// Use -update-golden to save stdout as the expected content, see
+// golden.AssertWith.
+func (a *Asserter) StdoutGolden(filename string) *Asserter {
+	a.t.Helper()
+	golden.AssertWith(&dumpT{a}, a.r.Stdout, filename)
+	return a
+}
+
+// StderrGolden checks stderr equals the content of the golden file,
+// see StdoutGolden.
+func (a *Asserter) StderrGolden(filename string) *Asserter {
+	a.t.Helper()
+	golden.AssertWith(&dumpT{a}, a.r.Stderr, filename)
+	return a
+}
+
+func (a *Asserter) contains(name, got string, exp []string) {
+	a.t.Helper()
+	for _, e := range exp {
+		if !strings.Contains(got, e) {
+			a.fail("%s missing %q", name, e)
+		}
+	}
+}
+
+func (a *Asserter) matches(name, got, expr string) {
+	a.t.Helper()
+	re, err := regexp.Compile("(?m)" + expr)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	if !re.MatchString(got) {
+		a.fail("%s does not match %q", name, expr)
+	}
+}
+
+func (a *Asserter) fail(format string, args ...interface{}) {
+	a.t.Helper()
+	a.t.Errorf("%s\n%s", fmt.Sprintf(format, args...), a.r.Dump())
+}
+
+// dumpT adds the result dump to golden failures
+type dumpT struct {
+	*Asserter
+}
+
+func (d *dumpT) Errorf(format string, args ...interface{}) {
+	d.t.Helper()
+	d.fail(format, args...)
+}
+
+func (d *dumpT) Helper()                { d.t.Helper() }
+func (d *dumpT) Fatal(v ...interface{}) { d.t.Fatal(v...) }
+
+// ----------------------------------------
+
+// Case is a command line and its expected result, see RunCases.
+type Case struct {
+	Args     []string // including command name
+	ExitCode int
+	Stdout   string // regular expression, ignored if empty
+	Stderr   string // regular expression, if empty none is expected
+}
+
+// RunCases runs each case as a subtest using Run.
+func RunCases[T Shell](t *testing.T, main func(T), cases ...Case) {
+	t.Helper()
+	for _, c := range cases {
+		t.Run(strings.Join(c.Args, " "), func(t *testing.T) {
+			c.check(Run(t, main, c.Args...).Assert(t))
+		})
+	}
+}
+
+func (c *Case) check(a *Asserter) {
+	a.t.Helper()
+	a.ExitCode(c.ExitCode)
+	if c.Stdout != "" {
+		a.StdoutMatches(c.Stdout)
+	}
+	if c.Stderr == "" {
+		a.NoStderr()
+		return
+	}
+	a.StderrMatches(c.Stderr)
+}
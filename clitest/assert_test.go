@@ -0,0 +1,56 @@
+package clitest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAsserter(t *testing.T) {
+	Run(t, greet, "greet", "John").Assert(t).
+		ExitCode(0).
+		StdoutContains("hello John").
+		StdoutMatches(`^hello \w+$`).
+		NoStderr()
+
+	Run(t, greet, "greet").Assert(t).
+		ExitCode(2).
+		StderrContains("missing").
+		StderrMatches("^missing name$")
+}
+
+func TestAsserter_fails(t *testing.T) {
+	rec := &recorder{TB: t}
+	Run(t, greet, "greet").Assert(rec).
+		ExitCode(0).
+		StdoutContains("hello").
+		StdoutMatches("hello").
+		StderrContains("x").
+		StderrMatches("x").
+		NoStderr()
+	got := rec.String()
+	if n := strings.Count(got, "$ greet"); n != 6 {
+		t.Errorf("expected 6 failures with dump, got %v\n%s", n, got)
+	}
+}
+
+func TestAsserter_golden(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "greet.golden")
+	os.WriteFile(file, []byte("hello John\n"), 0o644)
+	Run(t, greet, "greet", "John").Assert(t).StdoutGolden(file)
+
+	sh := NewShell(t)
+	rec := &recorder{TB: t}
+	sh.Assert(rec).StderrGolden(file)
+	if !strings.Contains(rec.String(), "$ /noname-tcmd") {
+		t.Error(rec.String())
+	}
+}
+
+func TestRunCases(t *testing.T) {
+	RunCases(t, greet,
+		Case{Args: []string{"greet", "John"}, Stdout: "^hello John$"},
+		Case{Args: []string{"greet"}, ExitCode: 2, Stderr: "missing"},
+	)
+}
@@ -0,0 +1,62 @@
+package clitest
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// diskFS uses package os and is rooted at /, it's the file system of
+// shells created with NewShellT.
+type diskFS struct{}
+
+func (diskFS) Open(name string) (fs.File, error) {
+	if err := validPath("open", name); err != nil {
+		return nil, err
+	}
+	return os.Open(osName(name))
+}
+
+func (diskFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := validPath("write", name); err != nil {
+		return err
+	}
+	return os.WriteFile(osName(name), data, perm)
+}
+
+func (diskFS) MkdirAll(name string, perm fs.FileMode) error {
+	if err := validPath("mkdir", name); err != nil {
+		return err
+	}
+	return os.MkdirAll(osName(name), perm)
+}
+
+func (diskFS) Remove(name string) error {
+	if err := validPath("remove", name); err != nil {
+		return err
+	}
+	return os.Remove(osName(name))
+}
+
+// osName returns the operating system name of a valid path, e.g.
+// tmp/a.txt is /tmp/a.txt
+func osName(name string) string {
+	if filepath.VolumeName(name) != "" {
+		return filepath.FromSlash(name)
+	}
+	return filepath.FromSlash("/" + name)
+}
+
+// fsName returns name, relative to dir unless absolute, as a path in
+// a file system rooted at /, see osName.
+func fsName(dir, name string) string {
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	name = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
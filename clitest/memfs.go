@@ -0,0 +1,92 @@
+package clitest
+
+import (
+	"io/fs"
+	"sync"
+	"testing/fstest"
+	"time"
+)
+
+// NewMemFS returns an empty in-memory file system.
+func NewMemFS() *MemFS {
+	return &MemFS{files: make(fstest.MapFS)}
+}
+
+// MemFS is an in-memory read and write file system, safe for
+// concurrent use. Names must be valid, see fs.ValidPath.
+type MemFS struct {
+	mu    sync.Mutex
+	files fstest.MapFS
+}
+
+// Open opens the named file for reading.
+func (m *MemFS) Open(name string) (fs.File, error) {
+	if err := validPath("open", name); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.Open(name)
+}
+
+// ReadFile returns the content of the named file.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	if err := validPath("read", name); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.ReadFile(name)
+}
+
+// WriteFile writes data to the named file, replacing any existing.
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := validPath("write", name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = &fstest.MapFile{
+		Data:    append([]byte{}, data...),
+		Mode:    perm,
+		ModTime: time.Now(),
+	}
+	return nil
+}
+
+// MkdirAll creates the named directory, parents are implicit.
+func (m *MemFS) MkdirAll(name string, perm fs.FileMode) error {
+	if err := validPath("mkdir", name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = &fstest.MapFile{
+		Mode:    fs.ModeDir | perm,
+		ModTime: time.Now(),
+	}
+	return nil
+}
+
+// Remove removes the named file or directory.
+func (m *MemFS) Remove(name string) error {
+	if err := validPath("remove", name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, found := m.files[name]; !found {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+// validPath returns fs.ErrInvalid if name is not valid, see
+// fs.ValidPath
+func validPath(op, name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return nil
+}
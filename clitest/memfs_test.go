@@ -0,0 +1,38 @@
+package clitest
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func TestMemFS(t *testing.T) {
+	m := NewMemFS()
+	m.WriteFile("a/b.txt", []byte("hello"), 0o644)
+	m.MkdirAll("c", 0o755)
+	if entries, _ := fs.ReadDir(m, "."); len(entries) != 2 {
+		t.Fatal(entries)
+	}
+	if data, _ := m.ReadFile("a/b.txt"); string(data) != "hello" {
+		t.Errorf("%q", data)
+	}
+	if err := m.Remove("a/b.txt"); err != nil {
+		t.Error(err)
+	}
+	if err := m.Remove("a/b.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Error(err)
+	}
+}
+
+func TestMemFS_invalid(t *testing.T) {
+	m := NewMemFS()
+	for _, name := range []string{"/a.txt", "./a.txt", "a/../b"} {
+		err := m.WriteFile(name, nil, 0o644)
+		if !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("WriteFile %q: %v", name, err)
+		}
+		if _, err := m.Open(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Open %q: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package clitest
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// Run calls main with a shell from NewShell(t, args...) and returns
+// the result, see RunShell.
+func Run[T Shell](t testing.TB, main func(T), args ...string) *Result {
+	t.Helper()
+	cmd, err := command(main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return run(NewShell(t, args...), cmd)
+}
+
+// RunShell calls main with the given shell in a separate goroutine.
+// Exit and Fatal stop main, as they would in a real command, ie. code
+// following e.g. Basic.Parse is not run on usage errors. Panics are
+// recovered and returned in the result. Use the shell type your
+// command expects as T, e.g.
+//
+//	res := clitest.RunShell(sh, func(sh cmdline.Shell) { ... })
+//
+// Panics if *ShellT does not implement T.
+func RunShell[T Shell](sh *ShellT, main func(T)) *Result {
+	cmd, err := command(main)
+	if err != nil {
+		panic(err)
+	}
+	return run(sh, cmd)
+}
+
+// Shell constrains the shell type of commands, e.g. cmdline.Shell or
+// *ShellT.
+type Shell interface {
+	Args() []string
+	Exit(code int)
+}
+
+// command returns main called with a *ShellT as T, an error if
+// *ShellT does not implement T.
+func command[T Shell](main func(T)) (func(*ShellT), error) {
+	if _, ok := any(&ShellT{}).(T); !ok {
+		return nil, fmt.Errorf("%T: *ShellT does not implement argument", main)
+	}
+	return func(sh *ShellT) { main(any(sh).(T)) }, nil
+}
+
+func run(sh *ShellT, main func(*ShellT)) *Result {
+	res := &Result{Shell: sh}
+	done := make(chan struct{})
+	sh.stop = func() {
+		res.Exited = true
+		runtime.Goexit()
+	}
+	go func() {
+		defer close(done)
+		defer func() { res.Panic = recover() }()
+		main(sh)
+	}()
+	<-done
+	sh.stop = nil
+	res.ExitCode = sh.ExitCode
+	res.Stdout, res.Stderr = sh.Out.String(), sh.Err.String()
+	return res
+}
+
+// Result of running a command, see Run.
+type Result struct {
+	Shell    *ShellT
+	ExitCode int
+	Exited   bool // true if Exit or Fatal was called
+	Stdout   string
+	Stderr   string
+	Panic    interface{} // recovered value if main panicked
+}
+
+// Dump returns a transcript of the command, see ShellT.Dump. A panic
+// is included last.
+func (r *Result) Dump() string {
+	dump := r.Shell.Dump()
+	if r.Panic != nil {
+		dump += fmt.Sprintf("\npanic: %v", r.Panic)
+	}
+	return dump
+}
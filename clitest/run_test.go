@@ -0,0 +1,58 @@
+package clitest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	res := Run(t, greet, "greet")
+	if res.ExitCode != 2 || !res.Exited {
+		t.Error("Exit not called", res.ExitCode)
+	}
+	if strings.Contains(res.Stdout, "never written") {
+		t.Error("Exit did not stop the command")
+	}
+	if res.Stderr != "missing name\n" {
+		t.Errorf("%q", res.Stderr)
+	}
+}
+
+func TestRun_fatal(t *testing.T) {
+	res := Run(t, func(sh *ShellT) {
+		sh.Fatal("failed")
+		fmt.Fprintln(sh.Stdout(), "never written")
+	})
+	if res.ExitCode != 1 || res.Stdout != "" || res.Stderr != "failed\n" {
+		t.Error(res.Dump())
+	}
+}
+
+func TestRun_panic(t *testing.T) {
+	res := Run(t, func(sh *ShellT) {
+		var m map[string]int
+		m["x"] = 1
+	})
+	if res.Panic == nil || res.Exited {
+		t.Error("panic not recovered")
+	}
+	if got := res.Dump(); !strings.Contains(got, "panic: ") {
+		t.Error(got)
+	}
+}
+
+// otherShell is not a ShellT
+type otherShell struct{}
+
+func (otherShell) Args() []string { return nil }
+func (otherShell) Exit(int)       {}
+
+func TestRunShell_wrongShellType(t *testing.T) {
+	defer func() {
+		if e := recover(); e == nil {
+			t.Error("expected panic")
+		}
+	}()
+	RunShell(NewShell(t), func(otherShell) {})
+}
@@ -0,0 +1,286 @@
+package clitest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gregoryv/cmdline/internal/shlex"
+)
+
+var updateScripts = flag.Bool(
+	"update-scripts", false, "Update cmp files in test scripts",
+)
+
+// RunScripts runs each script matching the pattern, e.g.
+// testdata/*.txt, as a subtest, see RunScript.
+func RunScripts[T Shell](t *testing.T, pattern string, main func(T)) {
+	t.Helper()
+	files, err := filepath.Glob(pattern)
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no scripts matching %q %v", pattern, err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			RunScript(t, file, main)
+		})
+	}
+}
+
+// RunScript runs the test script in the given file against main,
+// which is called with a *ShellT for each command. Use the shell type
+// your command expects as T, e.g.
+//
+//	clitest.RunScript(t, "testdata/login.txt", func(sh cmdline.Shell) {
+//		...
+//	})
+//
+// A script is a txtar archive, ie. a comment followed by files. The
+// comment holds one instruction per line, empty lines and lines
+// starting with # are ignored.
+//
+//	$ mycmd -a 'x y'  run main with the arguments, see cmdline.Split
+//	exit 2            expected exit code of last command, default 0
+//	stdout REGEXP     stdout of last command matches
+//	! stderr REGEXP   stderr of last command does not match
+//	cmp stdout FILE   stdout of last command equals the file
+//	cmp NAME FILE     file written by a command equals the file
+//	env KEY=VALUE     set environment variable
+//	stdin FILE        use the file as stdin of next command
+//
+// The files, e.g.
+//
+//	-- input.txt --
+//	content
+//
+// are written to the shell file system, see ShellT.FS, before the
+// first command.
+// Exit and Fatal stop main. Run tests with -update-scripts to rewrite
+// cmp files with the actual output.
+func RunScript[T Shell](t *testing.T, file string, main func(T)) {
+	t.Helper()
+	cmd, err := command(main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newScript(t, file, data)
+	s.main = cmd
+	s.run()
+}
+
+func newScript(t testing.TB, file string, data []byte) *script {
+	s := &script{
+		t:       t,
+		file:    file,
+		archive: parseArchive(string(data)),
+		dir:     t.TempDir(),
+		files:   NewMemFS(),
+		env:     make(map[string]string),
+	}
+	s.builtins = map[string]func(arg string) error{
+		"exit":   s.exit,
+		"stdout": s.match(&s.stdout),
+		"stderr": s.match(&s.stderr),
+		"cmp":    s.cmp,
+		"env":    s.setenv,
+		"stdin":  s.setStdin,
+	}
+	return s
+}
+
+type script struct {
+	t       testing.TB
+	file    string
+	archive *archive
+	main    func(*ShellT)
+
+	dir      string // working directory
+	files    *MemFS // shared by all commands
+	env      map[string]string
+	stdin    string // of next command
+	builtins map[string]func(arg string) error
+
+	// result of last command
+	stdout, stderr string
+	code           int
+	checked        bool // if exit code is checked
+	dump           string
+
+	lineno  int  // of current instruction
+	negate  bool // if instruction starts with !
+	updated bool // if any cmp file is updated
+}
+
+func (s *script) run() {
+	s.writeFiles()
+	for i, line := range strings.Split(s.archive.comment, "\n") {
+		s.lineno = i + 1
+		s.fail(s.exec(strings.TrimSpace(line)))
+	}
+	s.fail(s.checkExit())
+	s.save()
+}
+
+// fail reports the error, if any, with the transcript of last
+// command.
+func (s *script) fail(err error) {
+	if err == nil {
+		return
+	}
+	s.t.Errorf("%s:%d: %v\n%s", s.file, s.lineno, err, s.dump)
+}
+
+func (s *script) exec(line string) error {
+	if line == "" || line[0] == '#' {
+		return nil
+	}
+	s.negate = strings.HasPrefix(line, "! ")
+	line = strings.TrimPrefix(line, "! ")
+	if strings.HasPrefix(line, "$ ") {
+		return s.commandLine(line[2:])
+	}
+	name, arg, _ := strings.Cut(line, " ")
+	fn, found := s.builtins[name]
+	if !found {
+		return fmt.Errorf("unknown instruction %q", name)
+	}
+	return fn(strings.TrimSpace(arg))
+}
+
+// commandLine runs main with arguments split from line, see
+// cmdline.Split.
+func (s *script) commandLine(line string) error {
+	args, err := shlex.Split(line)
+	if err != nil {
+		return err
+	}
+	return s.command(args)
+}
+
+// command runs main with the given arguments.
+func (s *script) command(args []string) error {
+	s.fail(s.checkExit())
+	sh := &ShellT{Env: map[string]string{"PWD": s.dir}, args: args}
+	sh.dir, sh.Files = s.dir, s.files
+	for k, v := range s.env {
+		sh.Env[k] = v
+	}
+	sh.In.WriteString(s.stdin)
+	s.stdin = ""
+	res := run(sh, s.main)
+	s.stdout, s.stderr = res.Stdout, res.Stderr
+	s.code, s.checked = res.ExitCode, false
+	s.dump = res.Dump()
+	if res.Panic != nil {
+		return fmt.Errorf("panic: %v", res.Panic)
+	}
+	return nil
+}
+
+// checkExit returns an error if the exit code of last command is not
+// checked and not 0.
+func (s *script) checkExit() error {
+	if s.checked || s.code == 0 {
+		return nil
+	}
+	s.checked = true
+	return fmt.Errorf("unexpected exit %v", s.code)
+}
+
+func (s *script) exit(arg string) error {
+	exp, err := strconv.Atoi(arg)
+	if err != nil {
+		return err
+	}
+	s.checked = true
+	if s.code != exp {
+		return fmt.Errorf("got exit %v, expected %v", s.code, exp)
+	}
+	return nil
+}
+
+// match returns an instruction checking the given output against a
+// regular expression.
+func (s *script) match(out *string) func(string) error {
+	return func(arg string) error {
+		re, err := regexp.Compile("(?m)" + arg)
+		if err != nil {
+			return err
+		}
+		if re.MatchString(*out) != s.negate {
+			return nil
+		}
+		if s.negate {
+			return fmt.Errorf("unexpected match for %q", arg)
+		}
+		return fmt.Errorf("no match for %q", arg)
+	}
+}
+
+func (s *script) cmp(arg string) error {
+	name, file, _ := strings.Cut(arg, " ")
+	out, err := s.output(name)
+	if err != nil {
+		return err
+	}
+	if *updateScripts {
+		s.updated = s.archive.set(file, out) || s.updated
+		return nil
+	}
+	if exp, _ := s.archive.get(file); out != exp {
+		return fmt.Errorf("%s differs from %s:\n%s", name, file, out)
+	}
+	return nil
+}
+
+// output returns stdout, stderr or content of the named file written
+// by a command.
+func (s *script) output(name string) (string, error) {
+	got := map[string]string{"stdout": s.stdout, "stderr": s.stderr}
+	if out, found := got[name]; found {
+		return out, nil
+	}
+	data, err := s.files.ReadFile(fsName(s.dir, name))
+	return string(data), err
+}
+
+func (s *script) setenv(arg string) error {
+	k, v, found := strings.Cut(arg, "=")
+	if !found {
+		return fmt.Errorf("env %q: expected KEY=VALUE", arg)
+	}
+	s.env[k] = v
+	return nil
+}
+
+func (s *script) setStdin(arg string) error {
+	data, err := s.files.ReadFile(fsName(s.dir, arg))
+	s.stdin = string(data)
+	return err
+}
+
+func (s *script) writeFiles() {
+	for _, f := range s.archive.files {
+		s.files.WriteFile(fsName(s.dir, f.name), []byte(f.data), 0o644)
+	}
+}
+
+// save writes the script if updated.
+func (s *script) save() {
+	if !s.updated {
+		return
+	}
+	data := []byte(s.archive.String())
+	if err := os.WriteFile(s.file, data, 0o644); err != nil {
+		s.t.Error(err)
+	}
+}
@@ -0,0 +1,92 @@
+package clitest
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// greet is a command used in test scripts
+func greet(sh *ShellT) {
+	names := sh.Args()[1:]
+	if len(names) == 0 {
+		fmt.Fprintln(sh.Stderr(), "missing name")
+		sh.Exit(2)
+		fmt.Fprintln(sh.Stdout(), "never written")
+	}
+	if len(names) == 1 && names[0] == "-" {
+		s := bufio.NewScanner(sh.Stdin())
+		s.Scan()
+		names = []string{s.Text()}
+	}
+	for _, name := range names {
+		fmt.Fprintln(sh.Stdout(), greeting(sh), name)
+	}
+}
+
+func greeting(sh *ShellT) string {
+	if v := sh.Getenv("GREETING"); v != "" {
+		return v
+	}
+	return "hello"
+}
+
+// save writes the second argument to the file named by the first
+func save(sh *ShellT) {
+	args := sh.Args()
+	if len(args) != 3 {
+		sh.Fatal("usage: save FILE TEXT")
+	}
+	sh.WriteFile(args[1], []byte(args[2]+"\n"))
+}
+
+func TestRunScripts(t *testing.T) {
+	RunScript(t, "testdata/greet.txt", greet)
+	RunScript(t, "testdata/save.txt", save)
+}
+
+func TestRunScript_fails(t *testing.T) {
+	cases := map[string]string{
+		"$ greet\n":                        "unexpected exit 2",
+		"$ greet John\nexit 1\n":           "got exit 0, expected 1",
+		"$ greet John\nstdout Eve\n":       "no match",
+		"$ greet John\n! stdout John\n":    "unexpected match",
+		"$ greet John\ncmp stdout x.txt\n": "differs",
+		"nosuch\n":                         "unknown instruction",
+	}
+	for script, exp := range cases {
+		rec := &recorder{TB: t}
+		s := newScript(rec, "x.txt", []byte(script))
+		s.main = greet
+		s.run()
+		if !strings.Contains(rec.String(), exp) {
+			t.Errorf("%q: got %q, expected %q", script, rec, exp)
+		}
+	}
+}
+
+func TestRunScript_update(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "update.txt")
+	os.WriteFile(file, []byte("$ greet John\ncmp stdout out.txt\n"), 0o644)
+	*updateScripts = true
+	defer func() { *updateScripts = false }()
+	RunScript(t, file, greet)
+
+	data, _ := os.ReadFile(file)
+	if !strings.Contains(string(data), "-- out.txt --\nhello John\n") {
+		t.Error(string(data))
+	}
+}
+
+// recorder records errors instead of failing
+type recorder struct {
+	testing.TB
+	strings.Builder
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	fmt.Fprintf(&r.Builder, format, args...)
+}
@@ -2,21 +2,52 @@ package clitest
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"io/fs"
 	"io/ioutil"
-	"log"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"testing"
 
 	"github.com/gregoryv/nexus"
 )
 
+// NewShell returns a shell with a virtual working directory and
+// buffered outputs, useful during testing. The working directory is
+// t.TempDir(), which is removed when the test ends. Unlike NewShellT
+// no process wide state is changed, so it's safe to use with
+// t.Parallel. The file system, see FS, is in memory, use WriteFile to
+// add files before running a command. The first argument should be
+// name of command, just as in os.Args. If ommited /noname-tcmd is
+// used.
+func NewShell(t testing.TB, args ...string) *ShellT {
+	t.Helper()
+	if len(args) == 0 {
+		args = []string{"/noname-tcmd"}
+	}
+	wd := t.TempDir()
+	return &ShellT{
+		Env: map[string]string{
+			"PWD": wd,
+		},
+		Files: NewMemFS(),
+		args:  args,
+		dir:   wd,
+	}
+}
+
 // NewShellT returns a shell with temporary working directory and
-// buffered outputs, useful during testing.
+// buffered outputs, useful during testing. The file system is the
+// disk.
 // os.Chdir is called to change working directory to the temporary directory.
 // The first argument should be name of command, just as in os.Args. If ommited
 // /noname-tcmd is used. Temporary directory is based on that name.
+//
+// Deprecated: use NewShell, changing the process working directory
+// makes tests unusable with t.Parallel.
 func NewShellT(args ...string) *ShellT {
 	if len(args) == 0 {
 		args = []string{"/noname-tcmd"}
@@ -30,6 +61,7 @@ func NewShellT(args ...string) *ShellT {
 		},
 		args:     args,
 		dir:      wd,
+		tmp:      wd,
 		origin:   origin,
 		ExitCode: 0,
 	}
@@ -42,10 +74,16 @@ type ShellT struct {
 	Err      bytes.Buffer // Stderr
 	In       bytes.Buffer // Stdin
 	ExitCode int          // Set by method Exit
+	Terminal bool         // Stdin is a terminal, see IsTerminal
+	Files    *MemFS       // returned by FS, the disk is used if nil
 
-	args   []string
-	dir    string
-	origin string
+	args    []string
+	dir     string   // virtual working directory
+	tmp     string   // removed by Cleanup
+	origin  string   // empty if created with NewShell
+	secrets []string // see Mask
+	stop    func()   // called by Exit, stops the command
+	signals signals  // see SendSignal
 }
 
 func (s *ShellT) Getenv(key string) (v string) {
@@ -59,24 +97,102 @@ func (s *ShellT) Stdin() io.Reader       { return &s.In }
 func (s *ShellT) Stdout() io.Writer      { return &s.Out }
 func (s *ShellT) Stderr() io.Writer      { return &s.Err }
 
-// Exit sets ExitCode
+// FS returns Files, rooted at /, or the disk if Files is nil. Use
+// ReadFile and WriteFile for names relative to the working directory.
+func (s *ShellT) FS() fs.FS { return s.files() }
+
+// WriteFile writes data to the named file in the shell file system,
+// relative names are resolved with Path.
+func (s *ShellT) WriteFile(name string, data []byte) error {
+	return s.files().WriteFile(fsName(s.dir, name), data, 0o644)
+}
+
+// ReadFile returns the content of the named file in the shell file
+// system, relative names are resolved with Path.
+func (s *ShellT) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(s.files(), fsName(s.dir, name))
+}
+
+func (s *ShellT) files() writableFS {
+	if s.Files == nil {
+		return diskFS{}
+	}
+	return s.Files
+}
+
+type writableFS interface {
+	fs.FS
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// IsTerminal returns the Terminal field
+func (s *ShellT) IsTerminal() bool { return s.Terminal }
+
+// ReadPassword reads a line from In
+func (s *ShellT) ReadPassword() (string, error) {
+	line, err := s.In.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Path returns name relative to the working directory, unless it's
+// absolute.
+func (s *ShellT) Path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(s.dir, name)
+}
+
+// Chdir changes the working directory of the shell, relative names
+// are resolved with Path. The process working directory is not
+// changed.
+func (s *ShellT) Chdir(dir string) {
+	s.dir = s.Path(dir)
+	s.Env["PWD"] = s.dir
+}
+
+// Exit sets ExitCode. When run by a test script it also stops the
+// command.
 func (s *ShellT) Exit(code int) {
 	s.ExitCode = code
-	os.Chdir(s.origin)
+	s.restore()
+	if s.stop != nil {
+		s.stop()
+	}
 }
 
-// Fatal logs the given values and calls the Exit method
+// Fatal writes the given values to Err and calls the Exit method
 func (s *ShellT) Fatal(v ...interface{}) {
-	log.Println(v...)
-	os.Chdir(s.origin)
+	fmt.Fprintln(&s.Err, v...)
 	s.Exit(1)
 }
 
 // Cleanup removes temporary directory and restores the working
-// directory.
+// directory if created with NewShellT. Shells created with NewShell
+// are cleaned up when the test ends.
 func (s *ShellT) Cleanup() {
-	os.Chdir(s.origin)
-	os.RemoveAll(s.dir)
+	if s.origin == "" {
+		return
+	}
+	s.restore()
+	os.RemoveAll(s.tmp)
+}
+
+// restore changes the process working directory back to where it was
+// when created with NewShellT.
+func (s *ShellT) restore() {
+	if s.origin != "" {
+		os.Chdir(s.origin)
+	}
+}
+
+// Mask replaces the given value with ******** in dumps. It's called
+// by the parser for secret option values.
+func (s *ShellT) Mask(v string) {
+	s.secrets = append(s.secrets, v)
 }
 
 // Dump returns a dump of the command, see DumpTo
@@ -86,20 +202,36 @@ func (s *ShellT) Dump() string {
 	return b.String()
 }
 
-// DumpTo writes argument, stdout and stderr if any to the given writer
+// DumpTo writes argument, stdout and stderr if any to the given
+// writer. Masked values are replaced.
 func (s *ShellT) DumpTo(w io.Writer) error {
-	p, err := nexus.NewPrinter(w)
+	pairs := make([]string, 0, 2*len(s.secrets))
+	for _, v := range s.secrets {
+		pairs = append(pairs, v, "********")
+	}
+	p, err := nexus.NewPrinter(&maskWriter{w, strings.NewReplacer(pairs...)})
 	p.Print("$ ")
 	p.Print(strings.Join(s.Args(), " "))
 	p.Println()
-	io.Copy(p, &s.Out)
+	io.Copy(p, bytes.NewReader(s.Out.Bytes()))
 	p.Println()
 	p.Print("exit ", s.ExitCode)
 
 	if s.Err.Len() > 0 {
 		p.Println()
 		p.Println("STDERR:")
-		io.Copy(p, &s.Err)
+		io.Copy(p, bytes.NewReader(s.Err.Bytes()))
 	}
 	return *err
 }
+
+// maskWriter replaces values in each write
+type maskWriter struct {
+	w io.Writer
+	r *strings.Replacer
+}
+
+func (m *maskWriter) Write(p []byte) (int, error) {
+	_, err := m.r.WriteString(m.w, string(p))
+	return len(p), err
+}
@@ -1,6 +1,9 @@
 package clitest
 
 import (
+	"io/fs"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -56,3 +59,76 @@ func TestShellT_dumps(t *testing.T) {
 		t.Error(got)
 	}
 }
+
+func TestShellT_Mask(t *testing.T) {
+	sh := NewShellT("login", "--token", "s3cret")
+	defer sh.Cleanup()
+
+	sh.Mask("s3cret")
+	sh.Out.WriteString("using s3cret")
+	got := sh.Dump()
+	if strings.Contains(got, "s3cret") {
+		t.Error(got)
+	}
+}
+
+func TestNewShell(t *testing.T) {
+	t.Parallel()
+	origin, _ := os.Getwd()
+	sh := NewShell(t, "mycmd")
+
+	wd, _ := sh.Getwd()
+	if wd == origin {
+		t.Error("working directory not virtual")
+	}
+	if got, _ := os.Getwd(); got != origin {
+		t.Error("process working directory changed", got)
+	}
+	sh.Exit(1)
+	if got, _ := os.Getwd(); got != origin {
+		t.Error("process working directory changed on exit", got)
+	}
+}
+
+func TestShellT_Path(t *testing.T) {
+	t.Parallel()
+	sh := NewShell(t)
+	wd, _ := sh.Getwd()
+	if got := sh.Path("a.txt"); got != filepath.Join(wd, "a.txt") {
+		t.Error(got)
+	}
+	if got := sh.Path("/etc/hosts"); got != "/etc/hosts" {
+		t.Error(got)
+	}
+	sh.Chdir("sub")
+	if got, _ := sh.Getwd(); got != filepath.Join(wd, "sub") {
+		t.Error(got)
+	}
+}
+
+func TestNewShellT_disk(t *testing.T) {
+	sh := NewShellT("mycmd")
+	defer sh.Cleanup()
+
+	if err := os.WriteFile("a.txt", []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := sh.ReadFile("a.txt"); string(data) != "hello" {
+		t.Errorf("%q", data)
+	}
+}
+
+func TestShellT_WriteFile(t *testing.T) {
+	t.Parallel()
+	sh := NewShell(t)
+	wd, _ := sh.Getwd()
+	sh.Chdir("sub")
+	sh.WriteFile("a.txt", []byte("hello"))
+	if _, err := fs.Stat(sh.FS(), fsName(wd, "sub/a.txt")); err != nil {
+		t.Error(err)
+	}
+	sh.Chdir("..")
+	if data, _ := sh.ReadFile("sub/a.txt"); string(data) != "hello" {
+		t.Errorf("%q", data)
+	}
+}
@@ -0,0 +1,90 @@
+package clitest
+
+import (
+	"os"
+	"sync"
+)
+
+// signals relays signals sent with SendSignal to channels registered
+// with Notify.
+type signals struct {
+	mu       sync.Mutex
+	notified []notification
+	pending  []os.Signal // sent before anyone is notified
+}
+
+type notification struct {
+	c   chan<- os.Signal
+	sig []os.Signal // all if empty
+}
+
+// Notify relays signals sent with SendSignal to c, see
+// signal.Notify. Pending signals matching sig are sent immediately.
+func (s *ShellT) Notify(c chan<- os.Signal, sig ...os.Signal) {
+	s.signals.mu.Lock()
+	defer s.signals.mu.Unlock()
+	n := notification{c: c, sig: sig}
+	s.signals.notified = append(s.signals.notified, n)
+	pending := s.signals.pending
+	s.signals.pending = nil
+	for _, v := range pending {
+		s.signals.relay(v)
+	}
+}
+
+// Stop stops relaying signals to c, see signal.Stop.
+func (s *ShellT) Stop(c chan<- os.Signal) {
+	s.signals.mu.Lock()
+	defer s.signals.mu.Unlock()
+	keep := s.signals.notified[:0]
+	for _, n := range s.signals.notified {
+		if n.c != c {
+			keep = append(keep, n)
+		}
+	}
+	s.signals.notified = keep
+}
+
+// SendSignal simulates sending the signal to the command, e.g.
+// os.Interrupt for Ctrl-C. As for real signals it's not blocking,
+// ie. the signal is dropped if a channel is not ready. Signals sent
+// before the command calls Notify are kept until it does, so tests
+// need not synchronize with the command.
+func (s *ShellT) SendSignal(sig os.Signal) {
+	s.signals.mu.Lock()
+	defer s.signals.mu.Unlock()
+	s.signals.relay(sig)
+}
+
+// relay sends sig to all matching channels, or keeps it pending if
+// there are none.
+func (s *signals) relay(sig os.Signal) {
+	var sent bool
+	for _, n := range s.notified {
+		sent = n.send(sig) || sent
+	}
+	if !sent {
+		s.pending = append(s.pending, sig)
+	}
+}
+
+// send returns true if sig matches, even if dropped.
+func (n *notification) send(sig os.Signal) bool {
+	if !n.matches(sig) {
+		return false
+	}
+	select {
+	case n.c <- sig:
+	default:
+	}
+	return true
+}
+
+func (n *notification) matches(sig os.Signal) bool {
+	for _, v := range n.sig {
+		if v == sig {
+			return true
+		}
+	}
+	return len(n.sig) == 0
+}
@@ -0,0 +1,34 @@
+package clitest
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestShellT_SendSignal(t *testing.T) {
+	sh := NewShell(t)
+	c := make(chan os.Signal, 1)
+	sh.Notify(c, os.Interrupt)
+
+	sh.SendSignal(syscall.SIGTERM) // not matching, kept pending
+	sh.SendSignal(os.Interrupt)
+	if got := <-c; got != os.Interrupt {
+		t.Error(got)
+	}
+
+	all := make(chan os.Signal, 1)
+	sh.Notify(all) // pending SIGTERM is relayed
+	if got := <-all; got != syscall.SIGTERM {
+		t.Error(got)
+	}
+
+	sh.Stop(c)
+	sh.Stop(all)
+	sh.SendSignal(os.Interrupt)
+	select {
+	case got := <-c:
+		t.Error("stopped channel got", got)
+	default:
+	}
+}
@@ -0,0 +1,80 @@
+package cmdline
+
+import (
+	"fmt"
+	"sync"
+)
+
+// NewCommand returns a command defined by the given func, which
+// defines options, named arguments and groups on the parser and
+// returns the parsed values, e.g.
+//
+//	type config struct {
+//		verbose bool
+//		files   []string
+//	}
+//	cmd := cmdline.NewCommand(func(p *cmdline.Parser) config {
+//		return config{
+//			verbose: p.Flag("-v, --verbose"),
+//			files:   p.NamedArg("FILES...").Strings(),
+//		}
+//	})
+//	cfg, err := cmd.Parse([]string{"mycmd", "-v", "a.txt"})
+//
+// The options are used for each parser, e.g. WithResponseFiles,
+// which is off by default as arguments are often untrusted.
+func NewCommand[T any](
+	define func(p *Parser) T, opts ...ParserOption,
+) *Command[T] {
+	return &Command[T]{define: define, opts: opts, sh: DefaultShell}
+}
+
+// Command parses many argument vectors using the same definition.
+// Options are parsed as they are defined, so define is called with a
+// fresh parser for each parse. It must be pure, ie. only define and
+// return values, as it may run concurrently. Command is safe for
+// concurrent use.
+type Command[T any] struct {
+	define func(p *Parser) T
+	opts   []ParserOption
+
+	mu sync.Mutex
+	sh Shell
+}
+
+// SetShell sets the shell used for environment, files and output of
+// each parse, defaults to DefaultShell. Parses already started use
+// the previous shell.
+func (c *Command[T]) SetShell(sh Shell) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sh = sh
+}
+
+// parser returns a new parser using the current shell
+func (c *Command[T]) parser(args []string) *Parser {
+	c.mu.Lock()
+	sh := c.sh
+	c.mu.Unlock()
+	opts := append([]ParserOption{WithShell(sh)}, c.opts...)
+	return NewParser(append(opts, WithArgs(args...))...)
+}
+
+// Parse returns the values defined for the given arguments, the
+// first being the command name.
+func (c *Command[T]) Parse(args []string) (T, error) {
+	if len(args) == 0 {
+		var zero T
+		return zero, fmt.Errorf("missing command name")
+	}
+	p := c.parser(args)
+	v := c.define(p)
+	return v, p.Error()
+}
+
+// Usage returns the usage of the command with the given name.
+func (c *Command[T]) Usage(name string) *Usage {
+	p := c.parser([]string{name})
+	c.define(p)
+	return p.Usage()
+}
@@ -0,0 +1,100 @@
+package cmdline
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/gregoryv/cmdline/clitest"
+)
+
+type copyConfig struct {
+	verbose bool
+	src     []string
+	dst     string
+}
+
+func copyCommand() *Command[copyConfig] {
+	return NewCommand(func(p *Parser) copyConfig {
+		p.ReportAll()
+		verbose := p.Flag("-v, --verbose")
+		src := p.NamedArg("SRC...")
+		dst := p.NamedArg("DST")
+		return copyConfig{
+			verbose: verbose,
+			src:     src.Strings(),
+			dst:     dst.String(""),
+		}
+	})
+}
+
+func ExampleNewCommand() {
+	cmd := copyCommand()
+	for _, line := range []string{"cp a b c -v", "cp -x a b"} {
+		args, _ := Split(line)
+		cfg, err := cmd.Parse(args)
+		fmt.Printf("%+v %v\n", cfg, err)
+	}
+	cmd.Usage("cp").WriteTo(os.Stdout)
+	// output:
+	// {verbose:true src:[a b] dst:c} <nil>
+	// {verbose:false src:[a] dst:b} Unknown option: -x
+	// Usage: cp [OPTIONS] SRC... DST
+	//
+	// Options
+	//     -v, --verbose
+}
+
+func TestCommand_Parse_concurrent(t *testing.T) {
+	cmd := copyCommand()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dst := fmt.Sprint("dst", i)
+			cfg, err := cmd.Parse([]string{"cp", "src", dst})
+			if err != nil || cfg.dst != dst {
+				t.Error(cfg, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCommand_SetShell_concurrent(t *testing.T) {
+	cmd := copyCommand()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			cmd.SetShell(clitest.NewShell(t, "cp"))
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := cmd.Parse([]string{"cp", "a", "b"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCommand_Parse_responseFiles(t *testing.T) {
+	sh := clitest.NewShell(t, "cp")
+	sh.WriteFile("secret.txt", []byte("s3cret"))
+	cmd := copyCommand()
+	cmd.SetShell(sh)
+	cfg, err := cmd.Parse([]string{"cp", "@secret.txt", "dst"})
+	if err != nil || cfg.src[0] != "@secret.txt" {
+		t.Error("response file expanded", cfg, err)
+	}
+}
+
+func TestCommand_Parse_empty(t *testing.T) {
+	if _, err := copyCommand().Parse(nil); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,113 @@
+package cmdline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Deprecation describes a deprecated option or option name.
+type Deprecation struct {
+	// Name is the old name, e.g. --user. If empty the option itself
+	// is deprecated.
+	Name string
+
+	// Removal is the version in which it's removed, e.g. v2.0.0
+	Removal string
+
+	// Message is added to warnings and usage, optional.
+	Message string
+}
+
+// Deprecated adds deprecation metadata to the option. Using a
+// deprecated name writes a warning to the shell stderr and the value
+// is given to this option, its replacement. Use it before reading the
+// value. Deprecated names are not listed in usage, they are
+// annotated below the option.
+func (opt *Option) Deprecated(d Deprecation) *Option {
+	opt.deprecations = append(opt.deprecations, d)
+	return opt
+}
+
+// matchNames returns option names including deprecated ones.
+func (opt *Option) matchNames() []string {
+	names := opt.argNames()
+	for _, d := range opt.deprecations {
+		if d.Name != "" {
+			names = append(names, d.Name)
+		}
+	}
+	return names
+}
+
+// warnDeprecated writes a warning once if the token at index i uses
+// a deprecated name.
+func (opt *Option) warnDeprecated(i int) {
+	name := opt.toks.list[i].name
+	d, found := opt.deprecation(name)
+	if !found || opt.warned {
+		return
+	}
+	opt.warned = true
+	var use string
+	if d.Name != "" {
+		use = opt.replacement()
+	}
+	fmt.Fprintln(opt.shell().Stderr(), "Warning:", d.text(name, use))
+}
+
+// deprecation returns the deprecation of the given name, or the one
+// of the option itself if there is none.
+func (opt *Option) deprecation(name string) (Deprecation, bool) {
+	var option Deprecation
+	var found bool
+	for _, d := range opt.deprecations {
+		switch {
+		case d.Name == name:
+			return d, true
+		case d.Name == "" && !found:
+			option, found = d, true
+		}
+	}
+	return option, found
+}
+
+// replacement returns the last name not being an environment
+// variable, e.g. --username for -u, --username, $USERNAME
+func (opt *Option) replacement() string {
+	var name string
+	for _, n := range opt.argNames() {
+		if !strings.HasPrefix(n, "$") {
+			name = n
+		}
+	}
+	return name
+}
+
+// deprecationDoc returns usage annotations of deprecations
+func (opt *Option) deprecationDoc() []string {
+	lines := make([]string, len(opt.deprecations))
+	for i, d := range opt.deprecations {
+		name := d.Name
+		if name == "" {
+			name = "This option"
+		}
+		lines[i] = d.text(name, "")
+	}
+	return lines
+}
+
+// text returns e.g. "--user is deprecated, use --username, removed
+// in v2.0.0"
+func (d *Deprecation) text(name, use string) string {
+	parts := []string{name + " is deprecated"}
+	if use != "" {
+		parts = append(parts, "use "+use)
+	}
+	if d.Removal != "" {
+		parts = append(parts, "removed in "+d.Removal)
+	}
+	if d.Message != "" {
+		parts = append(parts, d.Message)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,65 @@
+package cmdline
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gregoryv/cmdline/clitest"
+)
+
+func ExampleOption_Deprecated() {
+	cli := NewParser()
+	cli.Option("-u, --username").Deprecated(Deprecation{
+		Name:    "--user",
+		Removal: "v2.0.0",
+	}).String("")
+	cli.Usage().WriteOptionsTo(os.Stdout)
+	// output:
+	// -u, --username : ""
+	//         --user is deprecated, removed in v2.0.0
+}
+
+func TestOption_Deprecated(t *testing.T) {
+	sh := clitest.NewShell(t, "login", "--user", "john", "--legacy")
+	cli := NewParser()
+	cli.SetShell(sh)
+	username := cli.Option("-u, --username").Deprecated(Deprecation{
+		Name:    "--user",
+		Removal: "v2.0.0",
+	}).String("")
+	cli.Option("--legacy").Deprecated(Deprecation{
+		Message: "it has no effect",
+	}).Bool(false)
+
+	if username != "john" {
+		t.Error("got", username)
+	}
+	if err := cli.Error(); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, sh.Err.String(),
+		"Warning: --user is deprecated, use --username, removed in v2.0.0\n",
+		"Warning: --legacy is deprecated, it has no effect\n",
+	)
+	var usage strings.Builder
+	cli.Usage().WriteTo(&usage)
+	assertContains(t, usage.String(),
+		"This option is deprecated, it has no effect",
+	)
+}
+
+func TestOption_Deprecated_exact_name_first(t *testing.T) {
+	sh := clitest.NewShell(t, "login", "--user", "john")
+	cli := NewParser(WithShell(sh))
+	cli.Option("-u, --username").Deprecated(Deprecation{
+		Message: "use --login",
+	}).Deprecated(Deprecation{
+		Name:    "--user",
+		Removal: "v2.0.0",
+	}).String("")
+
+	assertContains(t, sh.Err.String(),
+		"Warning: --user is deprecated, use --username, removed in v2.0.0\n",
+	)
+}
@@ -0,0 +1,157 @@
+package cmdline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ExitUsage is the exit code suggested for command line usage
+// errors.
+const ExitUsage = 2
+
+// ExitCode returns the exit code suggested by the given error, 0 if
+// err is nil and 1 if no code is suggested. Errors suggest an exit
+// code by implementing
+//
+//	ExitCode() int
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var e interface{ ExitCode() int }
+	if errors.As(err, &e) {
+		return e.ExitCode()
+	}
+	return 1
+}
+
+// Errors is a list of errors, written one per line. Same as errors
+// returned by errors.Join, errors.Is and errors.As inspect each of
+// them.
+type Errors []error
+
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e Errors) Unwrap() []error { return e }
+
+// UnknownOption is returned for arguments looking like options which
+// are not defined.
+type UnknownOption struct {
+	Name string // as given, e.g. --no-such
+}
+
+func (e *UnknownOption) Error() string {
+	return fmt.Sprintf("Unknown option: %s", e.Name)
+}
+
+func (e *UnknownOption) ExitCode() int { return ExitUsage }
+
+// MissingValue is returned for options given without a value.
+type MissingValue struct {
+	Option string // names of the option, e.g. -i, --integer
+}
+
+func (e *MissingValue) Error() string {
+	return fmt.Sprintf("Missing value: %s", e.Option)
+}
+
+func (e *MissingValue) ExitCode() int { return ExitUsage }
+
+// MissingOption is returned for required options not given.
+type MissingOption struct {
+	Option string // names of the option, e.g. -i, --integer
+}
+
+func (e *MissingOption) Error() string {
+	return fmt.Sprintf("Missing option: %s", e.Option)
+}
+
+func (e *MissingOption) ExitCode() int { return ExitUsage }
+
+// InvalidValue is returned for option values that cannot be parsed.
+type InvalidValue struct {
+	Option string // names of the option, e.g. -i, --integer
+	Value  string // raw value
+	Err    error  // cause
+}
+
+func (e *InvalidValue) Error() string {
+	return fmt.Sprintf("Invalid value %q for %s: %v", e.Value, e.Option, e.Err)
+}
+
+func (e *InvalidValue) Unwrap() error { return e.Err }
+func (e *InvalidValue) ExitCode() int { return ExitUsage }
+
+// InvalidDefault is returned for default values that cannot be
+// parsed. The default is given by the command, so it's not a usage
+// error and the suggested exit code is 1.
+type InvalidDefault struct {
+	Option string // names of the option, e.g. -d, --duration
+	Value  string // default value
+	Err    error  // cause
+}
+
+func (e *InvalidDefault) Error() string {
+	return fmt.Sprintf(
+		"Invalid default %q for %s: %v", e.Value, e.Option, e.Err,
+	)
+}
+
+func (e *InvalidDefault) Unwrap() error { return e.Err }
+
+// MissingArgument is returned for required named arguments not
+// given.
+type MissingArgument struct {
+	Name string // e.g. FILES...
+}
+
+func (e *MissingArgument) Error() string {
+	return fmt.Sprintf("Missing argument: %s", e.Name)
+}
+
+func (e *MissingArgument) ExitCode() int { return ExitUsage }
+
+// ArgumentCount is returned when a named argument is given too few
+// or too many values, see NamedArg.Between.
+type ArgumentCount struct {
+	Name     string // e.g. FILES...
+	Min, Max int    // Max -1 is unlimited
+	Got      int
+}
+
+func (e *ArgumentCount) Error() string {
+	var expect string
+	switch {
+	case e.Min == e.Max:
+		expect = fmt.Sprint(e.Min)
+	case e.Max == -1:
+		expect = fmt.Sprintf("at least %v", e.Min)
+	default:
+		expect = fmt.Sprintf("%v..%v", e.Min, e.Max)
+	}
+	return fmt.Sprintf(
+		"%s expects %s arguments, got %v", e.Name, expect, e.Got,
+	)
+}
+
+func (e *ArgumentCount) ExitCode() int { return ExitUsage }
+
+// InvalidGroupItem is returned when the selected item of a group
+// does not exist.
+type InvalidGroupItem struct {
+	Group string // named argument of the group, e.g. PHRASE
+	Name  string // as given
+}
+
+func (e *InvalidGroupItem) Error() string {
+	return fmt.Sprintf("Invalid %s: %s", e.Group, e.Name)
+}
+
+func (e *InvalidGroupItem) ExitCode() int { return ExitUsage }
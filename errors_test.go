@@ -0,0 +1,99 @@
+package cmdline
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func ExampleInvalidValue() {
+	cli := NewParser()
+	cli.setArgs([]string{"mycmd", "-i", "k"})
+	cli.Option("-i, --integer").Int(0)
+
+	var e *InvalidValue
+	if errors.As(cli.Error(), &e) {
+		fmt.Println(e.Option, e.Value, errors.Is(e, strconv.ErrSyntax))
+	}
+	fmt.Println(ExitCode(cli.Error()))
+	// output:
+	// -i, --integer k true
+	// 2
+}
+
+func Test_error_types(t *testing.T) {
+	cases := []struct {
+		args   string
+		define func(*Parser)
+		exp    interface{}
+	}{
+		{"cmd -x", func(p *Parser) {}, new(*UnknownOption)},
+		{"cmd -a", func(p *Parser) { p.Option("-a").String("") },
+			new(*MissingValue)},
+		{"cmd -a -b", func(p *Parser) { p.Option("-a").String("") },
+			new(*MissingValue)},
+		{"cmd -a=x", func(p *Parser) { p.Option("-a").Float64(0) },
+			new(*InvalidValue)},
+		{"cmd -a=x", func(p *Parser) { p.Option("-a").Enum("b", "c") },
+			new(*InvalidValue)},
+		{"cmd", func(p *Parser) { p.NamedArg("FILES...").Strings() },
+			new(*MissingArgument)},
+		{"cmd x", func(p *Parser) {
+			p.Group("Nouns", "NOUN").New("y", nil)
+			p.groups[0].Selected()
+		}, new(*InvalidGroupItem)},
+	}
+	for _, c := range cases {
+		t.Run(c.args, func(t *testing.T) {
+			cli := Parse(t, c.args)
+			c.define(cli)
+			err := cli.Error()
+			if !errors.As(err, c.exp) {
+				t.Errorf("%T: %v", err, err)
+			}
+			if code := ExitCode(err); code != ExitUsage {
+				t.Error("exit code", code)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if v := ExitCode(nil); v != 0 {
+		t.Error("nil:", v)
+	}
+	if v := ExitCode(fmt.Errorf("other")); v != 1 {
+		t.Error("other:", v)
+	}
+	wrapped := fmt.Errorf("wrapped: %w", &UnknownOption{Name: "-x"})
+	if v := ExitCode(wrapped); v != ExitUsage {
+		t.Error("wrapped:", v)
+	}
+}
+
+func ExampleParser_ReportAll() {
+	cli := NewParser()
+	cli.setArgs([]string{"mycmd", "-a=x", "-b=1", "-c=y", "-d"})
+	cli.ReportAll()
+	cli.Option("-a").Int(0)
+	cli.Option("-b").Int(0)
+	cli.Option("-c").Duration("1s")
+
+	fmt.Println(cli.Error())
+	// output:
+	// Invalid value "x" for -a: strconv.Atoi: parsing "x": invalid syntax
+	// Invalid value "y" for -c: time: invalid duration "y"
+	// Unknown option: -d
+}
+
+func Test_Errors_inspected_as_joined(t *testing.T) {
+	err := Errors{fmt.Errorf("other"), &MissingValue{Option: "-a"}}
+	var e *MissingValue
+	if !errors.As(err, &e) {
+		t.Error("As failed")
+	}
+	if code := ExitCode(err); code != ExitUsage {
+		t.Error("exit code", code)
+	}
+}
@@ -81,4 +81,4 @@ type runnable interface {
 
 type runfunc func()
 
-func (h runfunc) Run() { me() }
+func (h runfunc) Run() { h() }
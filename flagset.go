@@ -0,0 +1,102 @@
+package cmdline
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+)
+
+// ImportFlags defines an option for each flag in the flag set and
+// sets the flag values from the arguments, so code reading the flag
+// variables keeps working. Flags with one character names are named
+// -x, others -name, --name as both are accepted by package flag.
+func (b *Parser) ImportFlags(fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		b.importFlag(f)
+	})
+}
+
+func (b *Parser) importFlag(f *flag.Flag) {
+	names := "-" + f.Name
+	if len(f.Name) > 1 {
+		names += ", --" + f.Name
+	}
+	opt := b.Option(names, strings.Split(f.Usage, "\n")...)
+	if !isBoolFlag(f.Value) {
+		opt.Var(f.Value)
+		return
+	}
+	// as package flag, -v file.txt is a flag followed by an argument
+	opt.inlineOnly = true
+	def, _ := strconv.ParseBool(f.DefValue)
+	v := opt.Bool(def)
+	if opt.given && opt.err == nil {
+		f.Value.Set(strconv.FormatBool(v))
+	}
+}
+
+func isBoolFlag(v flag.Value) bool {
+	b, ok := v.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// FlagSet returns a flag set with a flag for each name of the
+// defined options, e.g. -n, --dry-run results in flags n and
+// dry-run. The flag values are the option values, so define options
+// before calling FlagSet. Defaults of hidden options are masked.
+// Setting a flag validates the value, but only options defined with
+// Var update your variables, see Option.Var.
+func (b *Parser) FlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(b.args[0], flag.ContinueOnError)
+	for _, opt := range b.options {
+		exportOption(fs, opt)
+	}
+	return fs
+}
+
+func exportOption(fs *flag.FlagSet, opt *Option) {
+	for _, name := range opt.argNames() {
+		if !isOption(name) {
+			continue
+		}
+		name = strings.TrimLeft(name, "-")
+		fs.Var(&optionValue{opt}, name, strings.Join(opt.doc, "\n"))
+		fs.Lookup(name).DefValue = maskedDefault(opt)
+	}
+}
+
+// optionValue exposes an option as flag.Value
+type optionValue struct {
+	opt *Option
+}
+
+// String returns the given or default value of the option.
+func (v *optionValue) String() string {
+	switch {
+	case v.opt == nil: // zero value used by flag.PrintDefaults
+		return ""
+	case v.opt.given:
+		return v.opt.value
+	}
+	return v.opt.defaultValue
+}
+
+// Set validates and parses s as the typed accessor of the option
+// does, any previous error is replaced. Values of options defined
+// with Var are set, values returned by other accessors are not
+// changed.
+func (v *optionValue) Set(s string) error {
+	opt := v.opt
+	opt.err = nil
+	opt.setValue(s)
+	if opt.err == nil && opt.parse != nil {
+		if err := opt.parse(s); err != nil {
+			opt.invalid(s, err)
+		}
+	}
+	return opt.err
+}
+
+func (v *optionValue) IsBoolFlag() bool {
+	return v.opt != nil && v.opt.boolean
+}
@@ -0,0 +1,89 @@
+package cmdline
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParser_ImportFlags(t *testing.T) {
+	fs := flag.NewFlagSet("legacy", flag.ContinueOnError)
+	verbose := fs.Bool("v", false, "verbose output")
+	name := fs.String("name", "john", "name of user")
+	timeout := fs.Duration("timeout", time.Second, "")
+	dry := fs.Bool("dry-run", true, "")
+
+	cli := NewParser()
+	cli.setArgs([]string{"legacy", "--name", "eve", "-timeout=2s",
+		"--dry-run=false", "-v", "file"})
+	cli.ImportFlags(fs)
+	arg := cli.NamedArg("FILE").String("")
+
+	if err := cli.Error(); err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprintln(*verbose, *name, *timeout, *dry, arg)
+	if exp := "true eve 2s false file\n"; got != exp {
+		t.Errorf("got %q, expected %q", got, exp)
+	}
+	var usage strings.Builder
+	cli.Usage().WriteTo(&usage)
+	assertContains(t, usage.String(), "-name, --name", "name of user")
+}
+
+func TestParser_ImportFlags_invalid(t *testing.T) {
+	fs := flag.NewFlagSet("legacy", flag.ContinueOnError)
+	fs.Int("n", 0, "")
+	cli := NewParser()
+	cli.setArgs([]string{"legacy", "-n", "x"})
+	cli.ImportFlags(fs)
+	if cli.Ok() {
+		t.Error("expected error")
+	}
+}
+
+func ExampleParser_FlagSet() {
+	cli := NewParser()
+	cli.setArgs([]string{"adduser", "--uid", "101"})
+	cli.Option("-u, --uid", "user id").Int(0)
+	cli.Option("-p, --password", "hidden").String("secret")
+
+	fs := cli.FlagSet()
+	fs.SetOutput(os.Stdout)
+	fmt.Println(fs.Lookup("uid").Value)
+	fs.PrintDefaults()
+	// output:
+	// 101
+	//   -p value
+	//     	 (default ********)
+	//   -password value
+	//     	 (default ********)
+	//   -u value
+	//     	user id (default 0)
+	//   -uid value
+	//     	user id (default 0)
+}
+
+func TestParser_FlagSet_Set(t *testing.T) {
+	cli := NewParser(WithArgs("adduser"))
+	cli.Option("--uid").Int(0)
+	var level upper
+	cli.Option("--level").Var(&level)
+
+	fs := cli.FlagSet()
+	if err := fs.Set("uid", "abc"); err == nil {
+		t.Error("expected error setting int option to abc")
+	}
+	fs.Set("uid", "101")
+	fs.Set("level", "DEBUG")
+	if err := cli.Error(); err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprint(fs.Lookup("uid").Value, " ", level)
+	if exp := "101 DEBUG"; got != exp {
+		t.Errorf("got %q, expected %q", got, exp)
+	}
+}
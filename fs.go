@@ -0,0 +1,96 @@
+package cmdline
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WritableFS is a file system commands can write to, see Shell.FS.
+type WritableFS interface {
+	fs.FS
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	MkdirAll(name string, perm fs.FileMode) error
+	Remove(name string) error
+}
+
+// WriteFile writes data to the named file of the file system, which
+// must implement WritableFS, e.g.
+//
+//	name := cmdline.FSPath(sh, "out.txt")
+//	err := cmdline.WriteFile(sh.FS(), name, data, 0644)
+func WriteFile(fsys fs.FS, name string, data []byte, perm fs.FileMode) error {
+	w, ok := fsys.(WritableFS)
+	if !ok {
+		return fmt.Errorf("write %s: read only file system", name)
+	}
+	return w.WriteFile(name, data, perm)
+}
+
+// FSPath returns name as a path in the shell file system, see
+// Shell.FS. Relative names are joined with the working directory,
+// e.g.
+//
+//	data, err := fs.ReadFile(sh.FS(), cmdline.FSPath(sh, "in.txt"))
+func FSPath(sh Shell, name string) string {
+	if !filepath.IsAbs(name) {
+		wd, _ := sh.Getwd()
+		name = filepath.Join(wd, name)
+	}
+	name = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// osFS uses package os and is rooted at /, names must be valid, see
+// fs.ValidPath.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	if err := validPath("open", name); err != nil {
+		return nil, err
+	}
+	return os.Open(osName(name))
+}
+
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := validPath("write", name); err != nil {
+		return err
+	}
+	return os.WriteFile(osName(name), data, perm)
+}
+
+func (osFS) MkdirAll(name string, perm fs.FileMode) error {
+	if err := validPath("mkdir", name); err != nil {
+		return err
+	}
+	return os.MkdirAll(osName(name), perm)
+}
+
+func (osFS) Remove(name string) error {
+	if err := validPath("remove", name); err != nil {
+		return err
+	}
+	return os.Remove(osName(name))
+}
+
+// validPath returns fs.ErrInvalid if name is not valid.
+func validPath(op, name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return nil
+}
+
+// osName returns the operating system name of a valid path, e.g.
+// tmp/a.txt is /tmp/a.txt
+func osName(name string) string {
+	if filepath.VolumeName(name) != "" {
+		return filepath.FromSlash(name)
+	}
+	return filepath.FromSlash("/" + name)
+}
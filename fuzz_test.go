@@ -0,0 +1,96 @@
+package cmdline
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func FuzzParser(f *testing.F) {
+	seeds := []string{
+		"", "-", "--", "-s=", "-s x -- -s y", "-i=1=2", `-s "x y"`,
+		"-s '", "a b c", "-b false", "a -t x", "--str=a=b", "-b=",
+		"-s -i", "--int 1 -- --int", "-x= -e", "c d",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		args := append([]string{"cmd"}, strings.Split(line, " ")...)
+		cli := NewParser()
+		cli.setArgs(args)
+		s := fuzzParse(cli)
+		checkConsumed(t, cli, args, s)
+	})
+}
+
+// fuzzParse defines a mix of options, named arguments and groups
+// and returns the value of -s, --str
+func fuzzParse(cli *Parser) string {
+	cli.Flag("-b, --bool")
+	s := cli.Option("-s, --str").String("")
+	cli.Option("-i, --int").Int(0)
+	cli.Option("-d, --duration").Duration("1s")
+	cli.Option("-m, --mode").Enum("a", "a", "b")
+	cli.Option("-e, $FUZZ_ENV").String("")
+	cli.Option("-x, ").String("") // empty last name
+	cli.NamedArg("FILE").Optional().String("")
+	grp := cli.Group("Items", "ITEM")
+	grp.New("a", func(p *Parser) interface{} {
+		return p.Option("-t").String("")
+	})
+	grp.New("b", func(p *Parser) interface{} {
+		return p.NamedArg("ARGS...").Strings()
+	})
+	grp.Selected()
+	cli.Group("Empty", "EMPTY").Selected()
+	cli.Error()
+	cli.Usage().WriteTo(io.Discard)
+	return s
+}
+
+// checkConsumed fails if the value of -s, --str given as separate
+// argument is also in Args.
+func checkConsumed(t *testing.T, cli *Parser, args []string, s string) {
+	v, found := separateValue(args[1:], "-s", "--str")
+	if !found || v != s {
+		return
+	}
+	if count(cli.Args(), v) >= count(args[1:], v) {
+		t.Errorf("consumed value %q in Args %q", v, cli.Args())
+	}
+}
+
+// separateValue returns the argument following the first of names
+// before any --.
+func separateValue(args []string, names ...string) (string, bool) {
+	for i, arg := range args[:max(len(args)-1, 0)] {
+		if arg == "--" {
+			return "", false
+		}
+		if arg == names[0] || arg == names[1] {
+			return args[i+1], args[i+1] != "--"
+		}
+	}
+	return "", false
+}
+
+func count(list []string, v string) int {
+	var n int
+	for _, s := range list {
+		if s == v {
+			n++
+		}
+	}
+	return n
+}
+
+func FuzzSplit(f *testing.F) {
+	for _, seed := range []string{`a 'b c' "d\"e" f\ g # x`, `"`, `\`} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		Split(line)
+		unquote(line)
+	})
+}
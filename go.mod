@@ -7,9 +7,11 @@ require (
 	github.com/gregoryv/nexus v0.7.0
 	github.com/gregoryv/qual v0.4.3
 	github.com/gregoryv/web v0.26.1
+	golang.org/x/term v0.25.0
 )
 
 require (
 	github.com/gregoryv/gocyclo v0.1.1 // indirect
 	github.com/sergi/go-diff v1.3.1 // indirect
+	golang.org/x/sys v0.26.0 // indirect
 )
@@ -0,0 +1,167 @@
+// Package shlex splits and unquotes words the way a POSIX shell
+// does, see cmdline.Split.
+package shlex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Split splits s into words, see cmdline.Split.
+func Split(s string) ([]string, error) {
+	sp := &splitter{src: s, words: make([]string, 0)}
+	for sp.pos < len(sp.src) {
+		if err := sp.next(); err != nil {
+			return nil, err
+		}
+	}
+	sp.end()
+	return sp.words, nil
+}
+
+// Unquote returns v unquoted if it consists of quoted parts and
+// escaped characters only, e.g. 'x'\”y'. Unlike Split there are no
+// comments or word separators.
+func Unquote(v string) (string, bool) {
+	sp := &splitter{src: v}
+	for sp.pos < len(sp.src) {
+		fn, found := quoting[sp.src[sp.pos]]
+		sp.pos++
+		if !found || fn(sp) != nil {
+			return v, false
+		}
+	}
+	return sp.word.String(), true
+}
+
+// quoting characters, see Unquote
+var quoting = map[byte]func(*splitter) error{
+	'\'': (*splitter).single,
+	'"':  (*splitter).double,
+	'\\': (*splitter).escape,
+}
+
+type splitter struct {
+	src string
+	pos int
+
+	words  []string
+	word   strings.Builder
+	inWord bool // true if word is started, it may be empty, e.g. ""
+}
+
+// special characters outside quotes
+var special = map[byte]func(*splitter) error{
+	'\'': (*splitter).single,
+	'"':  (*splitter).double,
+	'\\': (*splitter).escape,
+	'#':  (*splitter).comment,
+	' ':  (*splitter).space,
+	'\t': (*splitter).space,
+	'\n': (*splitter).space,
+	'\r': (*splitter).space,
+}
+
+func (sp *splitter) next() error {
+	c := sp.src[sp.pos]
+	sp.pos++
+	if fn, found := special[c]; found {
+		return fn(sp)
+	}
+	sp.add(c)
+	return nil
+}
+
+func (sp *splitter) add(c byte) {
+	sp.word.WriteByte(c)
+	sp.inWord = true
+}
+
+// end completes current word if any
+func (sp *splitter) end() {
+	if !sp.inWord {
+		return
+	}
+	sp.words = append(sp.words, sp.word.String())
+	sp.word.Reset()
+	sp.inWord = false
+}
+
+func (sp *splitter) space() error {
+	sp.end()
+	return nil
+}
+
+// single reads everything literally up to next single quote
+func (sp *splitter) single() error {
+	i := strings.IndexByte(sp.src[sp.pos:], '\'')
+	if i == -1 {
+		return fmt.Errorf("unterminated single quote")
+	}
+	sp.word.WriteString(sp.src[sp.pos : sp.pos+i])
+	sp.inWord = true
+	sp.pos += i + 1
+	return nil
+}
+
+// double reads up to next double quote, where backslash only escapes
+// $ ` " \ and newline.
+func (sp *splitter) double() error {
+	sp.inWord = true
+	for sp.pos < len(sp.src) {
+		c := sp.src[sp.pos]
+		sp.pos++
+		switch c {
+		case '"':
+			return nil
+		case '\\':
+			sp.doubleEscape()
+		default:
+			sp.word.WriteByte(c)
+		}
+	}
+	return fmt.Errorf("unterminated double quote")
+}
+
+func (sp *splitter) doubleEscape() {
+	const escaped = "$`\"\\\n"
+	last := sp.pos == len(sp.src)
+	if last || strings.IndexByte(escaped, sp.src[sp.pos]) == -1 {
+		sp.word.WriteByte('\\')
+		return
+	}
+	c := sp.src[sp.pos]
+	sp.pos++
+	if c != '\n' {
+		sp.word.WriteByte(c)
+	}
+}
+
+// escape adds next character as is, backslash newline continues the
+// line.
+func (sp *splitter) escape() error {
+	if sp.pos == len(sp.src) {
+		return fmt.Errorf("trailing backslash")
+	}
+	c := sp.src[sp.pos]
+	sp.pos++
+	if c != '\n' {
+		sp.add(c)
+	}
+	return nil
+}
+
+// comment skips to the end of line, # within a word is kept
+func (sp *splitter) comment() error {
+	if sp.inWord {
+		sp.add('#')
+		return nil
+	}
+	i := strings.IndexByte(sp.src[sp.pos:], '\n')
+	if i == -1 {
+		sp.pos = len(sp.src)
+		return nil
+	}
+	sp.pos += i + 1
+	return nil
+}
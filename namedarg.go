@@ -0,0 +1,287 @@
+package cmdline
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// NamedArg is a non option argument, see Parser.NamedArg.
+type NamedArg struct {
+	name     string
+	p        *Parser
+	index    int // in p.arguments
+	err      error
+	required bool
+
+	prompted []string // values given when prompted
+
+	// number of values, max -1 is unlimited
+	min, max int
+	bounded  bool // set by Between
+
+	defaultValue string
+	quoteValue   bool // in usage output
+	enumerated   []string
+	checks       []check // validations, with hints shown in usage
+}
+
+// Between sets the number of values a variadic argument accepts,
+// max -1 means unlimited. Use it before reading values. Panics if
+// min is greater than max.
+func (b *NamedArg) Between(min, max int) *NamedArg {
+	if max != -1 && min > max {
+		panic(fmt.Sprintf("%s: min %v > max %v", b.name, min, max))
+	}
+	b.min, b.max = min, max
+	b.bounded = true
+	b.required = min > 0
+	return b
+}
+
+// Optional marks the argument as not required. Use it when a
+// variadic argument preceding this one is read first, e.g. SRC... DST
+// [MODE].
+func (b *NamedArg) Optional() *NamedArg {
+	b.setRequired(false)
+	return b
+}
+
+// Exactly is short for Between(n, n).
+func (b *NamedArg) Exactly(n int) *NamedArg {
+	return b.Between(n, n)
+}
+
+// String returns the value of this NamedArg or the given default
+func (b *NamedArg) String(def string) string {
+	b.setRequired(def == "")
+	v := b.valuesOrPrompt()
+
+	if len(v) == 0 || v[0] == "" {
+		return def
+	}
+	return v[0]
+}
+
+// Strings returns the values of this argument. If no default is given
+// this NamedArg is considered required.
+func (b *NamedArg) Strings(def ...string) []string {
+	b.setRequired(len(def) == 0)
+	v := b.valuesOrPrompt()
+	switch {
+	case len(v) == 0 && b.required:
+		b.err = &MissingArgument{Name: b.name}
+	case len(v) == 0 && !b.required:
+		return def
+	}
+	return v
+}
+
+// setRequired sets the minimum number of values unless given by
+// Between.
+func (b *NamedArg) setRequired(v bool) {
+	if b.bounded {
+		return
+	}
+	b.required = v
+	b.min = 0
+	if v {
+		b.min = 1
+	}
+}
+
+// valuesOrPrompt returns values, prompting for them if missing and
+// required, see Parser.PromptMissing.
+func (b *NamedArg) valuesOrPrompt() []string {
+	v := b.values()
+	if len(v) > 0 || !b.required {
+		return v
+	}
+	answer, err := b.p.ask(question{
+		names: b.name, choices: b.enumerated,
+	})
+	if err != nil {
+		return v
+	}
+	b.prompted = []string{answer}
+	if isMulti(b.name) {
+		b.prompted, _ = Split(answer)
+	}
+	return b.prompted
+}
+
+// values returns the values of positional arguments given to this
+// argument, or prompted values.
+func (b *NamedArg) values() []string {
+	if b.prompted != nil {
+		return b.prompted
+	}
+	idx := b.p.allocate()[b.index]
+	v := make([]string, len(idx))
+	for i, j := range idx {
+		v[i] = b.p.toks.list[j].raw
+	}
+	return v
+}
+
+// error returns parsing error or if the number of values is not
+// within bounds.
+func (b *NamedArg) error() error {
+	switch {
+	case b.err != nil:
+		return b.err
+	case b.missing():
+		return &MissingArgument{Name: b.name}
+	case !b.bounded:
+		return nil
+	}
+	return b.countError()
+}
+
+// countError returns an error if the number of values is not within
+// bounds.
+func (b *NamedArg) countError() error {
+	counts, left := distribute(b.p.arguments, len(b.p.positional()))
+	got := counts[b.index]
+	if b.within(got, left) {
+		return nil
+	}
+	return &ArgumentCount{
+		Name: b.name, Min: b.min, Max: b.max, Got: got + left,
+	}
+}
+
+// missing returns true if required and all values are taken by a
+// preceding variadic argument, e.g. DST in SRC... DST.
+func (b *NamedArg) missing() bool {
+	if !b.required || b.prompted != nil || !b.afterVariadic() {
+		return false
+	}
+	return len(b.p.allocate()[b.index]) == 0
+}
+
+func (b *NamedArg) afterVariadic() bool {
+	for _, arg := range b.p.arguments[:b.index] {
+		if isMulti(arg.name) {
+			return true
+		}
+	}
+	return false
+}
+
+// within returns false if got is less than min or if values are
+// left when got reached max.
+func (b *NamedArg) within(got, left int) bool {
+	return got >= b.min && (got != b.max || left == 0)
+}
+
+// Int returns the value of this NamedArg as an int or the given
+// default.
+func (b *NamedArg) Int(def int) int {
+	return parseArg(b, def, strconv.Atoi)
+}
+
+// Uint returns the value of this NamedArg as an unsigned int or the
+// given default.
+func (b *NamedArg) Uint(def uint64) uint64 {
+	return parseArg(b, def, func(v string) (uint64, error) {
+		return strconv.ParseUint(v, 0, 64)
+	})
+}
+
+// Float64 returns the value of this NamedArg as a float64 or the
+// given default.
+func (b *NamedArg) Float64(def float64) float64 {
+	return parseArg(b, def, func(v string) (float64, error) {
+		return strconv.ParseFloat(v, 64)
+	})
+}
+
+// Duration returns the value of this NamedArg parsed with
+// time.ParseDuration or the given default.
+func (b *NamedArg) Duration(def string) time.Duration {
+	defDur, err := time.ParseDuration(def)
+	if err != nil {
+		b.invalidDefault(def, err)
+	}
+	return parseArg(b, defDur, time.ParseDuration)
+}
+
+// Url returns the value of this NamedArg parsed with url.Parse or
+// the given default.
+func (b *NamedArg) Url(def string) *url.URL {
+	defUrl, err := url.Parse(def)
+	if err != nil {
+		b.invalidDefault(def, err)
+	}
+	return parseArg(b, defUrl, url.Parse)
+}
+
+// Enum returns one of the possible values or the given default. It's
+// ok to only have one.
+func (b *NamedArg) Enum(def string, possible ...string) string {
+	if len(possible) == 0 {
+		possible = []string{def}
+	}
+	b.enumerated = possible
+	b.quoteValue = true
+	return parseArg(b, def, func(v string) (string, error) {
+		for _, e := range possible {
+			if e == v {
+				return v, nil
+			}
+		}
+		return def, fmt.Errorf("not one of %v", possible)
+	})
+}
+
+// Var sets the given value for each value of this NamedArg. The
+// current value is used as default.
+func (b *NamedArg) Var(v Value) {
+	b.setRequired(false)
+	b.defaultValue = v.String()
+	for _, s := range b.values() {
+		if err := v.Set(s); err != nil {
+			b.invalid(s, err)
+		}
+	}
+}
+
+// parseArg returns the first value of the argument parsed or def if
+// not given or invalid.
+func parseArg[T any](b *NamedArg, def T, parse func(string) (T, error)) T {
+	b.setRequired(false)
+	b.defaultValue = fmt.Sprintf("%v", def)
+	values := b.values()
+	if len(values) == 0 || values[0] == "" {
+		return def
+	}
+	v, err := parse(values[0])
+	if err != nil {
+		b.invalid(values[0], err)
+		return def
+	}
+	return v
+}
+
+func (b *NamedArg) invalid(v string, err error) {
+	if b.err == nil {
+		b.err = &InvalidValue{Option: b.name, Value: v, Err: err}
+	}
+}
+
+func (b *NamedArg) invalidDefault(def string, err error) {
+	if b.err == nil {
+		b.err = &InvalidDefault{Option: b.name, Value: def, Err: err}
+	}
+}
+
+func isMulti(v string) bool {
+	l := len(v)
+	if l <= 3 {
+		return false
+	}
+	end := v[l-3:]
+	return end == "..."
+}
@@ -0,0 +1,208 @@
+package cmdline
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ExampleNamedArg_Enum() {
+	os.Args = []string{"mycmd"} // just for this test
+	cli := NewParser()
+	cli.NamedArg("MODE").Enum("fast", "fast", "slow")
+	cli.NamedArg("COUNT").Range(1, 10).Int(1)
+
+	cli.Usage().WriteTo(os.Stdout)
+	// output:
+	// Usage: mycmd [OPTIONS] [MODE] [COUNT]
+	//
+	// Options
+	//
+	// Arguments
+	//     MODE : "fast" [fast slow]
+	//     COUNT : 1 (1..10)
+}
+
+func Test_named_arg_invalid_default(t *testing.T) {
+	cli := Parse(t, "cmd")
+	cli.NamedArg("PAUSE").Duration("1x")
+	var e *InvalidDefault
+	if !errors.As(cli.Error(), &e) || ExitCode(e) != 1 {
+		t.Error(cli.Error())
+	}
+}
+
+func Test_typed_named_args(t *testing.T) {
+	cli := Parse(t, "cmd 3 7 0.5 2s http://example.com slow")
+	var (
+		i   = cli.NamedArg("INT").Int(0)
+		u   = cli.NamedArg("UINT").Uint(0)
+		f   = cli.NamedArg("FLOAT").Float64(0)
+		d   = cli.NamedArg("DURATION").Duration("1s")
+		url = cli.NamedArg("URL").Url("")
+		e   = cli.NamedArg("MODE").Enum("fast", "fast", "slow")
+	)
+	if !cli.Ok() {
+		t.Fatal(cli.Error())
+	}
+	got := fmt.Sprintln(i, u, f, d, url.Host, e)
+	if got != "3 7 0.5 2s example.com slow\n" {
+		t.Error(got)
+	}
+}
+
+func Test_typed_named_args_defaults(t *testing.T) {
+	cli := Parse(t, "cmd")
+	d := cli.NamedArg("DURATION").Duration("1s")
+	i := cli.NamedArg("INT").Int(2)
+	if !cli.Ok() || d != time.Second || i != 2 {
+		t.Error(cli.Error(), d, i)
+	}
+}
+
+func Test_invalid_typed_named_args(t *testing.T) {
+	cases := map[string]func(*NamedArg){
+		"x":  func(a *NamedArg) { a.Int(0) },
+		"-1": func(a *NamedArg) { a.Uint(0) },
+		"y":  func(a *NamedArg) { a.Float64(0) },
+		"2x": func(a *NamedArg) { a.Duration("1s") },
+		"%z": func(a *NamedArg) { a.Url("") },
+		"b":  func(a *NamedArg) { a.Enum("a", "a", "c") },
+		"c":  func(a *NamedArg) { a.Var(new(upper)) },
+	}
+	for arg, fn := range cases {
+		t.Run(arg, func(t *testing.T) {
+			cli := Parse(t, "cmd -- "+arg)
+			fn(cli.NamedArg("ARG"))
+			var e *InvalidValue
+			if !errors.As(cli.Error(), &e) || e.Value != arg {
+				t.Error(cli.Error())
+			}
+		})
+	}
+}
+
+func Test_named_arg_Var(t *testing.T) {
+	cli := Parse(t, "cmd A B")
+	var v upper
+	cli.NamedArg("LETTERS...").Var(&v)
+	if !cli.Ok() || v.String() != "AB" {
+		t.Error(cli.Error(), v)
+	}
+}
+
+func Test_option_Var(t *testing.T) {
+	cli := Parse(t, "cmd -l x")
+	v := upper("A")
+	cli.Option("-l").Var(&v)
+	if cli.Ok() {
+		t.Error("expected failure for lower case")
+	}
+}
+
+// upper accepts upper case values only
+type upper string
+
+func (u *upper) String() string { return string(*u) }
+
+func (u *upper) Set(v string) error {
+	if strings.ToUpper(v) != v {
+		return fmt.Errorf("not upper case")
+	}
+	*u += upper(v)
+	return nil
+}
+
+func ExampleNamedArg_Between() {
+	os.Args = []string{"cp", "a", "b", "dir"} // just for this test
+	cli := NewParser()
+	src := cli.NamedArg("SRC...").Between(1, 3)
+	dst := cli.NamedArg("DST")
+	mode := cli.NamedArg("MODE").Optional()
+	fmt.Println(src.Strings(), dst.String(""), mode.String("copy"))
+
+	cli.Usage().WriteTo(os.Stdout)
+	// output:
+	// [a b] dir copy
+	// Usage: cp [OPTIONS] SRC... DST [MODE]
+	//
+	// Options
+}
+
+func Test_named_arg_bounds(t *testing.T) {
+	cases := []struct {
+		args string
+		ok   bool
+	}{
+		{"cp", false},
+		{"cp a", false},
+		{"cp a b", true},
+		{"cp a b c", true},
+		{"cp a b c d", true},
+		{"cp a b c d e", false},
+	}
+	for _, c := range cases {
+		t.Run(c.args, func(t *testing.T) {
+			cli := Parse(t, c.args)
+			src := cli.NamedArg("SRC...").Between(1, 3)
+			dst := cli.NamedArg("DST")
+			src.Strings()
+			dst.String("")
+			if err := cli.Error(); c.ok != (err == nil) {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_named_arg_missing_after_variadic(t *testing.T) {
+	cli := Parse(t, "cp a")
+	cli.NamedArg("SRC...").Strings()
+	cli.NamedArg("DST").String("")
+	var e *MissingArgument
+	if !errors.As(cli.Error(), &e) || e.Name != "DST" {
+		t.Error(cli.Error())
+	}
+}
+
+func Test_named_arg_exactly(t *testing.T) {
+	cli := Parse(t, "cmd a")
+	cli.NamedArg("PAIR...").Exactly(2).Strings()
+	var e *ArgumentCount
+	if !errors.As(cli.Error(), &e) || e.Got != 1 {
+		t.Fatal(cli.Error())
+	}
+	if got := e.Error(); got != "PAIR... expects 2 arguments, got 1" {
+		t.Error(got)
+	}
+}
+
+func Test_named_arg_optional_after_required(t *testing.T) {
+	cli := Parse(t, "cmd a")
+	a := cli.NamedArg("A").String("")
+	b := cli.NamedArg("B").String("b")
+	if !cli.Ok() || a != "a" || b != "b" {
+		t.Error(cli.Error(), a, b)
+	}
+}
+
+func Test_named_arg_between_panics(t *testing.T) {
+	defer expectPanic(t)
+	cli := Parse(t, "cmd")
+	cli.NamedArg("A...").Between(2, 1)
+}
+
+func TestArgumentCount_Error(t *testing.T) {
+	cases := map[string]*ArgumentCount{
+		"A... expects at least 2 arguments, got 1": {"A...", 2, -1, 1},
+		"A... expects 1..3 arguments, got 4":       {"A...", 1, 3, 4},
+	}
+	for exp, e := range cases {
+		if got := e.Error(); got != exp {
+			t.Error(got)
+		}
+	}
+}
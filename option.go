@@ -7,11 +7,13 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/gregoryv/cmdline/internal/shlex"
 )
 
 // Option defines a command line option, ie. --username
 type Option struct {
-	args         []string // without command
+	toks         *tokens // without command
 	names        string
 	defaultValue string
 	enumerated   []string
@@ -19,20 +21,41 @@ type Option struct {
 	doc          []string
 	err          error
 
-	argIndex int // position in args for e.g. --username
-	valIndex int // position for option value, same as argIndex if e.g. --i=1
+	value  string             // as given in arguments or environment
+	given  bool               // true if value was found
+	checks []check            // validations of given value
+	parse  func(string) error // of typed accessor, see FlagSet
 
 	envMap func(string) string
+	p      *Parser // nil if created with NewOption
 
 	// usage does not show value
-	hidden bool
+	hidden       bool
+	required     bool // see Required
+	undocumented bool // see Undocumented
+	boolean      bool // if read with Bool or BoolOpt
+	inlineOnly   bool // flag value only inline, e.g. -v=false
+
+	deprecations []Deprecation // see Deprecated
+	warned       bool          // if a deprecated name is used
+}
+
+// Value is the interface of custom option and named argument
+// values, same as flag.Value.
+type Value interface {
+	String() string
+	Set(string) error
 }
 
 // NewOption returns an option defined by a comma separated list of
 // names and arguments to match against. Usually you would call
 // Parser.Option(names) over this.
 func NewOption(names string, args ...string) *Option {
-	return &Option{names: names, args: args, argIndex: -1, valIndex: -1}
+	return &Option{
+		names:  names,
+		toks:   newTokens(args),
+		envMap: os.Getenv,
+	}
 }
 
 func (opt *Option) setDefault(def interface{}) {
@@ -53,14 +76,17 @@ func (opt *Option) Int(def int) int {
 // IntOpt returns int value from the arguments or the given default value.
 func (opt *Option) IntOpt(def int) (int, *Option) {
 	opt.setDefault(def)
+	opt.parse = func(v string) error {
+		_, err := strconv.Atoi(v)
+		return err
+	}
 	v, err := opt.stringArg()
 	if err != nil {
-		opt.fail()
 		return def, opt
 	}
 	iv, err := strconv.Atoi(v)
 	if err != nil {
-		opt.fail()
+		opt.invalid(v, err)
 	}
 	return iv, opt
 }
@@ -89,14 +115,17 @@ func (opt *Option) Uint(def uint64) uint64 {
 // UintOpt returns an unsigned int option
 func (opt *Option) UintOpt(def uint64) (uint64, *Option) {
 	opt.setDefault(def)
+	opt.parse = func(v string) error {
+		_, err := strconv.ParseUint(v, 0, 64)
+		return err
+	}
 	v, err := opt.stringArg()
 	if err != nil {
-		opt.fail()
 		return def, opt
 	}
 	iv, err := strconv.ParseUint(v, 0, 64)
 	if err != nil {
-		opt.fail()
+		opt.invalid(v, err)
 	}
 	return iv, opt
 
@@ -109,21 +138,22 @@ func (opt *Option) Duration(def string) time.Duration {
 
 func (opt *Option) DurationOpt(def string) (time.Duration, *Option) {
 	opt.setDefault(def)
+	opt.parse = func(v string) error {
+		_, err := time.ParseDuration(v)
+		return err
+	}
 	defDur, err := time.ParseDuration(def)
 	if err != nil {
-		opt.fail()
-		opt.err = err
+		opt.err = &InvalidDefault{Option: opt.names, Value: def, Err: err}
 		return 0, opt
 	}
 	v, err := opt.stringArg()
 	if err != nil {
-		opt.fail()
 		return defDur, opt
 	}
 	dur, err := time.ParseDuration(v)
 	if err != nil {
-		opt.fail()
-		opt.err = err
+		opt.invalid(v, err)
 		return defDur, opt
 	}
 	return dur, opt
@@ -136,26 +166,42 @@ func (opt *Option) Url(def string) *url.URL {
 
 func (opt *Option) UrlOpt(def string) (*url.URL, *Option) {
 	opt.setDefault(def)
+	opt.parse = func(v string) error {
+		_, err := url.Parse(v)
+		return err
+	}
 	defUrl, err := url.Parse(def)
 	if err != nil {
-		opt.fail()
-		opt.err = err
+		opt.err = &InvalidDefault{Option: opt.names, Value: def, Err: err}
 		return nil, opt
 	}
 	v, err := opt.stringArg()
 	if err != nil {
-		opt.fail()
 		return defUrl, opt
 	}
 	u, err := url.Parse(v)
 	if err != nil {
-		opt.fail()
-		opt.err = err
+		opt.invalid(v, err)
 		return defUrl, opt
 	}
 	return u, opt
 }
 
+// Var sets the given value if the option is given. The current
+// value is used as default.
+func (opt *Option) Var(v Value) *Option {
+	opt.setDefault(v.String())
+	opt.parse = v.Set
+	s, err := opt.stringArg()
+	if err != nil || !opt.given {
+		return opt
+	}
+	if err := v.Set(s); err != nil {
+		opt.invalid(s, err)
+	}
+	return opt
+}
+
 // Enum same as EnumOpt but does not return the Option
 func (opt *Option) Enum(def string, possible ...string) string {
 	val, _ := opt.EnumOpt(def, possible...)
@@ -167,21 +213,31 @@ func (opt *Option) EnumOpt(def string, possible ...string) (string, *Option) {
 	if len(possible) == 0 {
 		possible = []string{def}
 	}
+	opt.enumerated = possible // used when prompting
 	val, opt := opt.StringOpt(def)
+	opt.parse = oneOf(possible)
 
 	if val != def {
-		index := make(map[string]interface{})
-		for _, e := range possible {
-			index[e] = nil
-		}
-		if _, found := index[val]; !found {
-			opt.err = fmt.Errorf("incorrect %s %q", opt.names, val)
+		if err := opt.parse(val); err != nil {
+			opt.invalid(val, err)
 		}
 	}
 	opt.enumerated = possible
 	return val, opt
 }
 
+// oneOf returns a parse func accepting only the possible values.
+func oneOf(possible []string) func(string) error {
+	return func(v string) error {
+		for _, e := range possible {
+			if v == e {
+				return nil
+			}
+		}
+		return fmt.Errorf("not one of %v", possible)
+	}
+}
+
 // String same as StringOpt but does not return the Option.
 func (opt *Option) String(def string) string {
 	val, _ := opt.StringOpt(def)
@@ -196,11 +252,10 @@ func (opt *Option) StringOpt(def string) (string, *Option) {
 	// todo distinquish between option not found and value not found
 	v, err := opt.stringArg()
 	if err != nil {
-		opt.fail()
 		return def, opt
 	}
 	if isOption(v) {
-		opt.fail()
+		opt.err = &MissingValue{Option: opt.names}
 	}
 	if v == "" {
 		return def, opt
@@ -208,17 +263,24 @@ func (opt *Option) StringOpt(def string) (string, *Option) {
 	return unquote(v), opt
 }
 
+// unquote removes quotes of a single quoted word, e.g. "x y" or
+// 'x "y', using the same quoting rules as Split. Backquotes are
+// removed as is, e.g. `x y`.
 func unquote(v string) string {
-	if len(v) < 2 {
+	if len(v) < 2 || !isQuoteChar(v[0]) {
 		return v
 	}
-
-	first := v[0]
-	last := v[len(v)-1]
-	if isQuoteChar(first) && first == last {
+	if v[0] == '`' && v[len(v)-1] == '`' {
 		return v[1 : len(v)-1]
 	}
-	return v
+	return unquoteWord(v)
+}
+
+// unquoteWord returns v unquoted if it consists of quoted parts and
+// escaped characters only, e.g. 'x'\”y', otherwise v as is.
+func unquoteWord(v string) string {
+	u, _ := shlex.Unquote(v)
+	return u
 }
 
 func isQuoteChar(v byte) bool {
@@ -230,66 +292,114 @@ func isQuoteChar(v byte) bool {
 	}
 }
 
+// Undocumented excludes the option from usage and completion. It's
+// parsed as any other option, e.g. for debug or internal settings.
+// Basic lists them with --help-all.
+func (opt *Option) Undocumented() *Option {
+	opt.undocumented = true
+	return opt
+}
+
+// Required makes the option fail with MissingOption if not given,
+// unless the parser prompts for it, see Parser.PromptMissing. Use it
+// before reading the value.
+func (opt *Option) Required() *Option {
+	opt.required = true
+	return opt
+}
+
 func (opt *Option) stringArg() (string, error) {
-	i, found := opt.find()
-	if found {
-		arg := opt.args[i]
-		opt.valIndex = i
-		// NamedArg is -i=value
-		eqIndex := strings.Index(arg, "=")
-		if eqIndex > 0 {
-			return arg[eqIndex+1:], nil
-		}
-		isLast := len(opt.args)-1 == i
-		if isLast {
-			opt.fail()
-			return "", fmt.Errorf("missing value")
-		}
-		opt.valIndex = i + 1
-		// NamedArg is -i
-		return opt.args[i+1], nil
+	v, found, err := opt.argValue(opt.matchNames())
+	switch {
+	case !found:
+		return opt.missing()
+	case err != nil:
+		opt.err = err
+		return "", err
 	}
-	return opt.envValueOrDefault(), nil
+	return opt.setValue(v), nil
 }
 
-// If last element in option names starts with $ expand it
-func (opt *Option) envValueOrDefault() string {
-	names := opt.argNames()
-	env := names[len(names)-1] // last element
-	if env[0] != '$' {
-		return opt.defaultValue
+// argValue returns the value of the first option matching any of the
+// given names.
+func (opt *Option) argValue(names []string) (string, bool, error) {
+	i, found := opt.toks.find(names)
+	if !found {
+		return "", false, nil
+	}
+	opt.warnDeprecated(i)
+	tok := opt.toks.list[i]
+	tok.used = true
+	// e.g. -i=value
+	if tok.kind == inlineToken {
+		return tok.value, true, nil
+	}
+	// e.g. -i value
+	v, ok := opt.toks.claim(i + 1)
+	if !ok {
+		return "", true, &MissingValue{Option: opt.names}
 	}
-	return os.Expand(env, opt.envMap)
+	return v, true, nil
 }
 
-func (opt *Option) argNames() []string {
-	return strings.Split(strings.ReplaceAll(opt.names, " ", ""), ",")
+// missing returns the value from environment or the default. If
+// required and not in the environment it's prompted for.
+func (opt *Option) missing() (string, error) {
+	v := opt.envValueOrDefault()
+	if opt.given || !opt.required {
+		return v, nil
+	}
+	v, err := opt.ask()
+	if err != nil {
+		opt.err = err
+		return "", err
+	}
+	return opt.setValue(v), nil
 }
 
-func (opt *Option) match(arg string) bool {
-	if !isOption(arg) {
-		return false
+func (opt *Option) ask() (string, error) {
+	if opt.p == nil {
+		return "", &MissingOption{Option: opt.names}
 	}
+	return opt.p.ask(question{
+		names:   opt.names,
+		doc:     opt.doc,
+		choices: opt.enumerated,
+		hidden:  opt.hidden,
+	})
+}
+
+// setValue records the given value and validates it.
+func (opt *Option) setValue(v string) string {
+	opt.value = v
+	opt.given = true
+	opt.validate()
+	return v
+}
+
+// If last element in option names starts with $ expand it, empty
+// values are ignored.
+func (opt *Option) envValueOrDefault() string {
 	names := opt.argNames()
-	argName, _ := nameAndValue(arg)
-	for _, name := range names {
-		if name == argName {
-			return true
-		}
+	env := names[len(names)-1] // last element
+	if !strings.HasPrefix(env, "$") {
+		return opt.defaultValue
+	}
+	v := os.Expand(env, opt.envMap)
+	if v == "" {
+		return opt.defaultValue
 	}
-	return false
+	return opt.setValue(v)
 }
 
-func nameAndValue(arg string) (string, string) {
-	parts := strings.Split(arg, "=")
-	if len(parts) > 1 {
-		return parts[0], parts[1]
-	}
-	return parts[0], ""
+func (opt *Option) argNames() []string {
+	return strings.Split(strings.ReplaceAll(opt.names, " ", ""), ",")
 }
 
+// isOption returns true if arg starts with '-', a single dash is
+// not an option.
 func isOption(arg string) bool {
-	return len(arg) > 0 && arg[0] == '-'
+	return len(arg) > 1 && arg[0] == '-'
 }
 
 // Bool returns bool value from the arguments or the given default value.
@@ -313,49 +423,28 @@ func (opt *Option) BoolOpt() (bool, *Option) {
 }
 
 func (opt *Option) boolArg() bool {
+	opt.boolean = true
+	opt.parse = func(v string) error {
+		_, err := ParseBool(v)
+		return err
+	}
 	value := opt.envValueOrDefault()
 
-	i, found := opt.find()
-	if found {
-		// also check if any value is given
-		val, isOption := opt.get(i + 1)
-		if isOption || val == "" {
-			value = "true"
-		} else {
-			value = val
-		}
+	if i, found := opt.find(); found {
+		opt.warnDeprecated(i)
+		value = opt.setValue(opt.toks.flagValue(i, !opt.inlineOnly))
 	}
 
 	v, err := ParseBool(value)
 	if err != nil {
-		opt.err = fmt.Errorf("Invalid bool: %w", err)
+		opt.invalid(value, err)
 	}
 	return v
 }
 
-// find returns the index of the given option and sets internal arg.Index
-// returns 0, false if not found
-func (opt *Option) find() (i int, found bool) {
-	for i, arg := range opt.args {
-		if opt.match(arg) {
-			opt.argIndex = i
-			return i, true
-		}
-	}
-	return 0, false
-}
-
-// get returns the argument and true if it starts with '-'
-func (opt *Option) get(i int) (string, bool) {
-	if i >= len(opt.args) {
-		return "", false
-	}
-	next := opt.args[i]
-	if len(next) == 0 {
-		return "", false
-	}
-	isOption := next[0:1] == "-"
-	return next, isOption
+// find returns the index of the first argument matching this option
+func (opt *Option) find() (int, bool) {
+	return opt.toks.find(opt.matchNames())
 }
 
 // ParseBool returns true if the string evaluates to a true
@@ -377,8 +466,13 @@ func ParseBool(v string) (bool, error) {
 	return false, fmt.Errorf("parse bool %q", v)
 }
 
-func (opt *Option) fail() {
-	opt.err = fmt.Errorf("Invalid option: %s", opt.names)
+// invalid sets an InvalidValue error, values of hidden options are
+// masked.
+func (opt *Option) invalid(v string, err error) {
+	if opt.hidden {
+		v, err = mask, maskError(err, v)
+	}
+	opt.err = &InvalidValue{Option: opt.names, Value: v, Err: err}
 }
 
 // Float64 returns float64
@@ -392,14 +486,17 @@ func (opt *Option) Float64(def float64) float64 {
 // default value.
 func (opt *Option) Float64Opt(def float64) (float64, *Option) {
 	opt.setDefault(def)
+	opt.parse = func(v string) error {
+		_, err := strconv.ParseFloat(v, 64)
+		return err
+	}
 	v, err := opt.stringArg()
 	if err != nil {
-		opt.fail()
 		return def, opt
 	}
 	iv, err := strconv.ParseFloat(v, 64)
 	if err != nil {
-		opt.fail()
+		opt.invalid(v, err)
 	}
 	return iv, opt
 }
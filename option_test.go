@@ -1,6 +1,7 @@
 package cmdline
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -112,6 +113,10 @@ func Test_incorrect_duration_wrong_unit(t *testing.T) {
 func Test_incorrect_duration_default(t *testing.T) {
 	cli := Parse(t, "cmd")
 	cli.Option("-d").Duration("1x")
+	var e *InvalidDefault
+	if !errors.As(cli.Error(), &e) || ExitCode(e) != 1 {
+		t.Error(cli.Error())
+	}
 	if cli.Ok() {
 		t.Error(cli.Error())
 	}
@@ -152,6 +157,10 @@ func Test_incorrect_url_missing(t *testing.T) {
 func Test_incorrect_url_default(t *testing.T) {
 	cli := Parse(t, "cmd")
 	cli.Option("-h, --host").Url("%gh&%ij")
+	var e *InvalidDefault
+	if !errors.As(cli.Error(), &e) || ExitCode(e) != 1 {
+		t.Error(cli.Error())
+	}
 	if cli.Ok() {
 		t.Error("not a valid url")
 	}
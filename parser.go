@@ -7,32 +7,53 @@ import (
 	"sync"
 )
 
-// NewBasicParser returns a parser including help options -h, --help.
-func NewBasicParser() *Basic {
-	return &Basic{Parser: NewParser()}
+// NewBasicParser returns a parser including help options -h,
+// --help. All parse errors are reported. See NewParser for options.
+func NewBasicParser(opts ...ParserOption) *Basic {
+	p := NewParser(opts...)
+	p.ReportAll()
+	return &Basic{Parser: p}
 }
 
 type Basic struct {
 	*Parser
 
 	defineHelp sync.Once // used to parse the help flag only once
-	help       bool
+	help       bool      // also if helpAll
+	helpAll    bool
+
+	version     bool
+	versionInfo *VersionInfo // nil unless EnableVersion is called
+
+	defineOnly bool // Parse exits with 0, see REPL.Complete
 }
 
 // Parse checks for errors or if the help flag is given writes usage
-// to os.Stdout
+// to os.Stdout. With --help-all undocumented options are included.
+// See EnableVersion for the version flag. On errors it exits with
+// the code suggested by the error, see ExitCode.
 func (b *Basic) Parse() {
 	b.defineHelp.Do(b.helpFlag)
 
 	switch {
+	case b.defineOnly:
+		b.sh.Exit(0)
+
 	case b.help:
-		b.Usage().WriteTo(b.Parser.sh.Stdout())
+		u := b.Usage()
+		u.all = b.helpAll
+		u.WriteTo(b.Parser.sh.Stdout())
+		b.sh.Exit(0)
+
+	case b.version:
+		b.writeVersion()
 		b.sh.Exit(0)
 
 	case !b.Ok():
-		fmt.Fprintln(b.sh.Stderr(), b.Error())
+		err := b.Error()
+		fmt.Fprintln(b.sh.Stderr(), err)
 		fmt.Fprintln(b.sh.Stderr(), "Try -h or --help, for more information")
-		b.sh.Exit(1)
+		b.sh.Exit(ExitCode(err))
 	}
 }
 
@@ -43,42 +64,127 @@ func (b *Basic) Usage() *Usage {
 	return b.Parser.Usage()
 }
 
+// helpFlag defines -h, --help and --help-all, the latter is only
+// documented if there are undocumented options.
 func (b *Basic) helpFlag() {
+	hide := len(documented(b.options)) == len(b.options)
 	b.help = b.Parser.Flag("-h, --help")
+	opt := b.Parser.Option("--help-all")
+	opt.undocumented = hide
+	b.helpAll, _ = opt.BoolOpt()
+	b.help = b.help || b.helpAll
 }
 
 // ----------------------------------------
 
-// DefaultShell is used by all new parsers.
+// DefaultShell is used by new parsers unless WithShell is given.
 var DefaultShell Shell = NewShellOS()
 
-// NewParser returns a parser using the DefaultShell
-func NewParser() *Parser {
-	sh := DefaultShell
+// NewParser returns a parser using the DefaultShell, its arguments
+// and environment, unless changed by the given options, e.g.
+//
+//	cli := cmdline.NewParser(cmdline.WithShell(sh))
+func NewParser(opts ...ParserOption) *Parser {
+	p := newParser(DefaultShell)
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.args == nil {
+		p.args = p.sh.Args()
+	}
+	p.setArgs(p.expand(p.args))
+	return p
+}
+
+// ParserOption configures a new parser, see NewParser.
+type ParserOption func(*Parser)
+
+// WithShell sets the shell of the parser, arguments and environment
+// are read from it.
+func WithShell(sh Shell) ParserOption {
+	return func(p *Parser) {
+		p.sh = sh
+		p.envMap = sh.Getenv
+	}
+}
+
+// WithArgs sets the arguments, the first being the command name,
+// instead of using those of the shell.
+func WithArgs(args ...string) ParserOption {
+	return func(p *Parser) {
+		p.args = args
+	}
+}
+
+// WithResponseFiles enables expansion of response files, e.g.
+// @args.txt is replaced with arguments read from file args.txt, see
+// Split for the format. Only use it for arguments you trust, files
+// are read from the shell file system.
+func WithResponseFiles() ParserOption {
+	return func(p *Parser) {
+		p.responseFiles = true
+	}
+}
+
+// WithEnv sets the func used to look up environment variables,
+// instead of using the shell.
+func WithEnv(getenv func(string) string) ParserOption {
+	return func(p *Parser) {
+		p.envMap = getenv
+	}
+}
+
+// newParser returns a parser without arguments, use setArgs before
+// defining options.
+func newParser(sh Shell) *Parser {
 	p := &Parser{
 		sh:      sh,
-		args:    sh.Args(),
 		options: make([]*Option, 0),
 		groups:  make([]*Group, 0),
 		envMap:  sh.Getenv,
+		itemsAt: -1,
 	}
 	p.usage = &Usage{Parser: p}
 	return p
 }
 
+// NewParserLine returns a parser for the given command line, split
+// into arguments using Split. The first word is the command. See
+// NewParser for options.
+func NewParserLine(line string, opts ...ParserOption) (*Parser, error) {
+	args, err := Split(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command line")
+	}
+	return NewParser(append(opts, WithArgs(args...))...), nil
+}
+
 // Parser groups arguments for option parsing and usage.
 type Parser struct {
 	sh Shell
 
 	args      []string // including command name as first element
+	toks      *tokens  // classified args without command name
 	options   []*Option
 	arguments []*NamedArg // required
 
 	groups []*Group
+	// index in toks from where arguments belong to a selected group
+	// item, -1 if none
+	itemsAt int
 
 	envMap func(string) string
 
 	usage *Usage
+
+	reportAll     bool // see ReportAll
+	prompt        bool // see PromptMissing
+	responseFiles bool // see WithResponseFiles
+
+	err error // e.g. failed to expand response files
 }
 
 // Parse checks parsing errors and exits on errors
@@ -89,13 +195,50 @@ func (b *Parser) Parse() {
 	}
 }
 
+// SetShell replaces the shell and parses its arguments, the
+// environment is not changed. Options must be defined after. Prefer
+// NewParser(WithShell(sh)).
 func (b *Parser) SetShell(sh Shell) {
 	b.sh = sh
-	b.args = sh.Args()
+	b.setArgs(b.expand(sh.Args()))
+}
+
+// expand returns args with response files expanded, if enabled, e.g.
+// @args.txt is replaced with arguments read from file args.txt. On
+// errors the arguments are returned as is.
+func (b *Parser) expand(args []string) []string {
+	if !b.responseFiles {
+		return args
+	}
+	more, err := expandArgs(b.sh, args[1:], 0)
+	b.err = err
+	if err != nil {
+		return args
+	}
+	return append([]string{args[0]}, more...)
+}
+
+func (b *Parser) setArgs(args []string) {
+	b.args = args
+	b.toks = newTokens(args[1:])
 }
 
+// Group returns a new group of items selected by the named argument
+// at the current position. Arguments following the selected item
+// belong to it and are not available to this parser, ie. named
+// arguments must be defined before the group.
 func (b *Parser) Group(title, name string) *Group {
-	return b.group(title, name, b.NamedArg(name).String(""))
+	arg := b.NamedArg(name)
+	arg.setRequired(true) // never prompted for, the first is default
+	v := arg.values()
+	grp := b.group(title, name, strings.Join(v, ""))
+	grp.parent = b
+	at := b.allocate()[arg.index]
+	if len(at) > 0 && b.itemsAt == -1 {
+		grp.at = at[0]
+		b.itemsAt = at[0] + 1
+	}
+	return grp
 }
 
 // Preface is the same as Usage().Preface
@@ -106,7 +249,7 @@ func (b *Parser) Preface(lines ...string) {
 func (b *Parser) group(title, name, v string) *Group {
 	grp := &Group{
 		name:  name,
-		args:  b.Args(),
+		at:    -1,
 		title: title,
 		v:     v,
 		items: make([]*Item, 0),
@@ -130,8 +273,9 @@ func (b *Parser) addGroup(grp *Group) error {
 }
 
 type Group struct {
-	name string
-	args []string // needed for parsing extra options
+	name   string
+	parent *Parser
+	at     int // index of selected item in parent tokens
 
 	title string
 	v     string
@@ -149,24 +293,53 @@ func (b *Group) New(name string, any interface{}) *Item {
 	return item
 }
 
-// Selected returns the matching item. Defaults to the first in the group.
+// Selected returns the matching item. Defaults to the first in the
+// group, nil if the group is empty.
 func (b *Group) Selected() interface{} {
+	if len(b.items) == 0 {
+		return nil
+	}
 	i := b.items[0]
 	if b.v != "" {
 		var found bool
 		i, found = b.find(b.v)
 		if !found {
-			b.err = fmt.Errorf("invalid %s", b.name)
+			b.err = &InvalidGroupItem{Group: b.name, Name: b.v}
 			return nil
 		}
 	}
-	extra := NewParser()
-	extra.args = append([]string{b.title}, b.args...)
+	extra := b.parser(append([]string{i.Name}, b.itemArgs()...))
 	sel := i.Load(extra)
 	b.err = extra.Error()
 	return sel
 }
 
+// parser returns a parser for an item, derived from the parent with
+// the same shell, environment and settings.
+func (b *Group) parser(args []string) *Parser {
+	p := newParser(b.parent.sh)
+	p.envMap = b.parent.envMap
+	p.reportAll = b.parent.reportAll
+	p.prompt = b.parent.prompt
+	p.setArgs(args)
+	return p
+}
+
+// itemArgs returns the arguments following the selected item which
+// are not matched by options of the parent.
+func (b *Group) itemArgs() []string {
+	rest := make([]string, 0)
+	if b.at == -1 {
+		return rest
+	}
+	for _, tok := range b.parent.toks.list[b.at+1:] {
+		if !tok.used {
+			rest = append(rest, tok.raw)
+		}
+	}
+	return rest
+}
+
 func (b *Group) Title() string  { return b.title }
 func (b *Group) Items() []*Item { return b.items }
 
@@ -187,40 +360,55 @@ func (b *Parser) Ok() bool {
 	return b.Error() == nil
 }
 
-// Error returns first error of the given options.
+// Error returns first error of the given options, or all of them
+// as Errors if ReportAll has been called.
 func (b *Parser) Error() error {
-	err := b.parseFailed()
-	if err != nil {
-		return err
-	}
-	if len(b.groups) == 0 { // as groups are selected with non option argument
-		for _, arg := range b.Args() {
-			if isOption(arg) {
-				return fmt.Errorf("Unknown option: %v", arg)
-			}
-		}
+	errs := append(b.parseFailed(), b.unknownOptions()...)
+	switch {
+	case len(errs) == 0:
+		return nil
+	case b.reportAll:
+		return Errors(errs)
 	}
-	return nil
+	return errs[0]
 }
 
-func (b *Parser) parseFailed() error {
-	var err error
-	setErr := func(e error) {
+// ReportAll makes Error return all parse errors instead of only the
+// first one.
+func (b *Parser) ReportAll() {
+	b.reportAll = true
+}
+
+func (b *Parser) parseFailed() []error {
+	errs := make([]error, 0)
+	add := func(err error) {
 		if err != nil {
-			return
+			errs = append(errs, err)
 		}
-		err = e
 	}
+	add(b.err)
 	for _, opt := range b.options {
-		setErr(opt.err)
+		add(opt.err)
 	}
 	for _, arg := range b.arguments {
-		setErr(arg.err)
+		add(arg.error())
 	}
 	for _, grp := range b.groups {
-		setErr(grp.err)
+		add(grp.err)
+	}
+	return errs
+}
+
+// unknownOptions returns errors for options not matched. Options of
+// a selected group item are checked by its own parser.
+func (b *Parser) unknownOptions() []error {
+	errs := make([]error, 0)
+	for _, tok := range b.region() {
+		if tok.isOption() && !tok.used {
+			errs = append(errs, &UnknownOption{Name: tok.raw})
+		}
 	}
-	return err
+	return errs
 }
 
 // Option returns a new option with the given names.
@@ -239,7 +427,7 @@ func (b *Parser) parseFailed() error {
 //
 // means the values is masked when printed in the usage information.
 func (b *Parser) Option(names string, doclines ...string) *Option {
-	opt := NewOption(names, b.args[1:]...)
+	opt := &Option{names: names, toks: b.toks, p: b}
 	opt.envMap = b.envMap
 	opt.doc = make([]string, 0, len(doclines))
 	for _, line := range doclines {
@@ -266,18 +454,38 @@ func (b *Parser) Usage() *Usage {
 	return b.usage
 }
 
-// Args returns arguments not matched by any of the options
+// Args returns arguments not matched by any of the options, nor
+// belonging to a selected group item.
 func (b *Parser) Args() []string {
 	rest := make([]string, 0)
-	for i, arg := range b.args[1:] {
-		//		fmt.Println("a:", arg)
-		if !b.wasMatched(i) {
-			rest = append(rest, arg)
+	for _, tok := range b.region() {
+		if !tok.used && tok.kind != terminatorToken {
+			rest = append(rest, tok.raw)
 		}
 	}
 	return rest
 }
 
+// region returns tokens not belonging to a selected group item.
+func (b *Parser) region() []*token {
+	if b.itemsAt == -1 {
+		return b.toks.list
+	}
+	return b.toks.list[:b.itemsAt]
+}
+
+// positional returns indexes of non option tokens in region not
+// matched by any of the options.
+func (b *Parser) positional() []int {
+	idx := make([]int, 0)
+	for i, tok := range b.region() {
+		if tok.kind == positionalToken && !tok.used {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
 // Argn returns the n:th of remaining arguments starting at 0.
 func (b *Parser) Argn(n int) string {
 	rest := b.Args()
@@ -287,79 +495,84 @@ func (b *Parser) Argn(n int) string {
 	return ""
 }
 
-func (b *Parser) wasMatched(i int) bool {
+// String returns the arguments with values of secret and hidden
+// options masked.
+func (b *Parser) String() string {
+	args := append([]string{}, b.args...)
 	for _, opt := range b.options {
-		if opt.argIndex == i || opt.valIndex == i {
-			return true
+		if opt.hidden {
+			maskArg(args[1:], opt)
 		}
 	}
-	return false
+	return fmt.Sprintf("Parser: %s", strings.Join(args, " "))
 }
 
-func (b *Parser) String() string {
-	return fmt.Sprintf("Parser: %s", strings.Join(b.args, " "))
+// maskArg masks the value of the given option in args, which are
+// those classified as tokens.
+func maskArg(args []string, opt *Option) {
+	i, found := opt.find()
+	if !found {
+		return
+	}
+	tok := opt.toks.list[i]
+	switch {
+	case tok.kind == inlineToken:
+		args[i] = tok.name + "=" + mask
+	case i+1 < len(args) && opt.toks.list[i+1].kind == valueToken:
+		args[i+1] = mask
+	}
 }
 
-// NamedArg returns an named argument
+// NamedArg returns an named argument. Names ending with ... are
+// variadic, e.g. FILES... Positional arguments are distributed among
+// named arguments when their values are read, so when a variadic
+// argument is followed by others, e.g. SRC... DST, define all before
+// reading any of them.
 func (b *Parser) NamedArg(name string) *NamedArg {
 	arg := &NamedArg{
-		name: name,
-		v:    b.parseMultiArg(name),
+		name:  name,
+		p:     b,
+		index: len(b.arguments),
+		min:   1,
+		max:   1,
 	}
-	b.arguments = append(b.arguments, arg)
-	return arg
-}
-
-func (b *Parser) parseMultiArg(name string) []string {
 	if isMulti(name) {
-		return b.Args()
-	}
-	// parse one argument
-	v := b.Argn(len(b.arguments))
-	if v == "" {
-		return nil
+		arg.max = -1
 	}
-	return []string{v}
-}
-
-// ----------------------------------------
-
-type NamedArg struct {
-	name     string
-	v        []string
-	err      error
-	required bool
+	b.arguments = append(b.arguments, arg)
+	return arg
 }
 
-// String returns the value of this NamedArg or the given default
-func (b *NamedArg) String(def string) string {
-	b.required = (def == "")
-	v := b.v
-
-	if len(v) == 0 || v[0] == "" {
-		return def
+// allocate returns indexes of positional tokens for each named
+// argument. Each argument first gets its minimum, remaining are
+// given in order up to each maximum.
+func (b *Parser) allocate() [][]int {
+	pos := b.positional()
+	counts, _ := distribute(b.arguments, len(pos))
+	res := make([][]int, len(counts))
+	var at int
+	for i, n := range counts {
+		res[i] = pos[at : at+n]
+		at += n
 	}
-	return v[0]
+	return res
 }
 
-// Strings returns the values of this argument. If no default is given
-// this NamedArg is considered required.
-func (b *NamedArg) Strings(def ...string) []string {
-	b.required = (len(def) == 0)
-	switch {
-	case len(b.v) == 0 && b.required:
-		b.err = fmt.Errorf("missing %s", b.name)
-	case len(b.v) == 0 && !b.required:
-		return def
+// distribute returns number of values for each argument and number
+// of values left.
+func distribute(args []*NamedArg, left int) ([]int, int) {
+	counts := make([]int, len(args))
+	for i, arg := range args {
+		counts[i] = min(arg.min, left)
+		left -= counts[i]
 	}
-	return b.v
-}
-
-func isMulti(v string) bool {
-	l := len(v)
-	if l <= 3 {
-		return false
+	for i, arg := range args {
+		n := left
+		if arg.max != -1 {
+			n = min(arg.max-counts[i], left)
+		}
+		counts[i] += n
+		left -= n
 	}
-	end := v[l-3:]
-	return end == "..."
+	return counts, left
 }
@@ -110,7 +110,7 @@ func Test_basic_parser_checks_errors(t *testing.T) {
 	cli.SetShell(sh)
 	log.SetOutput(ioutil.Discard)
 	cli.Parse()
-	if sh.ExitCode != 1 {
+	if sh.ExitCode != ExitUsage {
 		t.Error(sh.Dump())
 	}
 }
@@ -124,6 +124,26 @@ func Test_parser_checks_errors(t *testing.T) {
 		t.Error(sh.Dump())
 	}
 }
+func ExampleNewParserLine() {
+	cli, _ := NewParserLine(`mycmd -n 'John Doe' --note="a \"b\"" file`)
+	var (
+		name = cli.Option("-n").String("")
+		note = cli.Option("--note").String("")
+		file = cli.NamedArg("FILE").String("")
+	)
+	fmt.Printf("%s|%s|%s\n", name, note, file)
+	// output:
+	// John Doe|a "b"|file
+}
+
+func Test_parser_line_errors(t *testing.T) {
+	for _, line := range []string{"", "  # only comment", `cmd "x`} {
+		if _, err := NewParserLine(line); err == nil {
+			t.Errorf("%q: expected error", line)
+		}
+	}
+}
+
 func Test_parser_constructor_uses_osArgs(t *testing.T) {
 	p := NewParser()
 	if !reflect.DeepEqual(p.args, os.Args) {
@@ -131,6 +151,35 @@ func Test_parser_constructor_uses_osArgs(t *testing.T) {
 	}
 }
 
+func TestNewParser_options(t *testing.T) {
+	env := map[string]string{"NAME": "john"}
+	cli := NewParser(
+		WithArgs("mycmd", "-v"),
+		WithEnv(func(k string) string { return env[k] }),
+	)
+	verbose := cli.Flag("-v")
+	name := cli.Option("-n, $NAME").String("")
+	if !verbose || name != "john" {
+		t.Error("got", verbose, name)
+	}
+}
+
+func TestNewParser_WithShell(t *testing.T) {
+	sh := clitest.NewShell(t, "mycmd", "hello")
+	sh.Env["TOKEN"] = "secret"
+	cli := NewParser(WithShell(sh))
+	phrases := cli.Group("Phrases", "PHRASE")
+	var token string
+	phrases.New("hello", func(p *Parser) interface{} {
+		token = p.Option("-t, $TOKEN").String("")
+		return nil
+	})
+	phrases.Selected()
+	if token != "secret" {
+		t.Errorf("item option got %q", token)
+	}
+}
+
 func Test_groups_are_unique(t *testing.T) {
 	defer expectPanic(t)
 	cli := Parse(t, "ls -h")
@@ -180,6 +229,12 @@ func Test_parser_string_option_quoted(t *testing.T) {
 		{args: []string{"mycmd", `-q='x "y'`}, q: `x "y`},
 		{args: []string{"mycmd", `-q`, `'x "y'`}, q: `x "y`},
 		{args: []string{"mycmd", "-q", "`x y`"}, q: "x y"},
+		{args: []string{"mycmd", "-q", `"x \"y\""`}, q: `x "y"`},
+		{args: []string{"mycmd", "-q", `'x'\''y'`}, q: `x'y`},
+		{args: []string{"mycmd", "-q", `"x" "y"`}, q: `"x" "y"`},
+		{args: []string{"mycmd", "-q", `"x`}, q: `"x`},
+		{args: []string{"mycmd", "-q", `"fix" #123`}, q: `"fix" #123`},
+		{args: []string{"mycmd", "-q", `"a"b`}, q: `"a"b`},
 	}
 	for _, c := range cases {
 		t.Run("", func(t *testing.T) {
@@ -205,17 +260,46 @@ func Test_single_group_item_is_selected(t *testing.T) {
 	}
 }
 
-func xTest_group_and_multi_argument(t *testing.T) {
-	cli := Parse(t, "run action conf1 conf2")
+func Test_group_owns_trailing_arguments(t *testing.T) {
+	cli := Parse(t, "run hello conf1 conf2")
 	phrases := cli.Group("Phrases", "PHRASE")
 	phrases.New("hello", nil)
 	phrases.Selected()
-	files := cli.NamedArg("FILES...").Strings("default")
+	cli.NamedArg("FILES...").Strings()
 	if cli.Ok() {
-		t.Errorf("expected failure when required FILES... is missing")
+		t.Errorf("expected failure when FILES... follow the group")
 	}
-	if !reflect.DeepEqual(files, []string{"conf1", "conf2"}) {
-		t.Error("got", files)
+}
+
+func Test_group_item_named_arguments(t *testing.T) {
+	cli := Parse(t, "run conf hello world -t 1 -v")
+	cli.Flag("-v")
+	conf := cli.NamedArg("CONF").String("")
+	phrases := cli.Group("Phrases", "PHRASE")
+	var who string
+	phrases.New("hello", func(p *Parser) interface{} {
+		p.Option("-t").Int(0)
+		who = p.NamedArg("WHO").String("")
+		return nil
+	})
+	phrases.Selected()
+	if !cli.Ok() {
+		t.Fatal(cli.Error())
+	}
+	if conf != "conf" || who != "world" {
+		t.Error("got", conf, who)
+	}
+	if got := cli.Args(); !reflect.DeepEqual(got, []string{"conf", "hello"}) {
+		t.Error("parent args", got)
+	}
+}
+
+func Test_multi_argument_after_named(t *testing.T) {
+	cli := Parse(t, "cp a b c")
+	cli.NamedArg("SRC").String("")
+	got := cli.NamedArg("FILES...").Strings()
+	if !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Error("got", got)
 	}
 }
 
@@ -0,0 +1,100 @@
+package cmdline
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PromptMissing makes the parser ask for values of required options
+// and named arguments not given, if the shell is an interactive
+// terminal. The shell must implement
+//
+//	IsTerminal() bool
+//	ReadPassword() (string, error)
+//
+// as ShellOS does. Values of options with the "hidden" docline are
+// read without echo.
+func (b *Parser) PromptMissing() {
+	b.prompt = true
+}
+
+// terminal is implemented by shells that can prompt for values
+type terminal interface {
+	IsTerminal() bool
+	ReadPassword() (string, error)
+}
+
+type question struct {
+	names   string   // of option or named argument
+	doc     []string // written before names
+	choices []string // written as a numbered menu
+	hidden  bool     // read without echo
+}
+
+// ask writes the question to stdout and returns the answer read from
+// stdin. MissingOption is returned if the parser does not prompt.
+func (b *Parser) ask(q question) (string, error) {
+	t, ok := b.sh.(terminal)
+	if !ok || !b.prompt || !t.IsTerminal() {
+		return "", &MissingOption{Option: q.names}
+	}
+	q.writeTo(b.sh.Stdout())
+	answer, err := b.readAnswer(t, q.hidden)
+	return q.choice(answer), err
+}
+
+// writeTo writes the doc lines, choices and names as prompt.
+func (q *question) writeTo(w io.Writer) {
+	for _, line := range q.doc {
+		fmt.Fprintln(w, line)
+	}
+	for i, c := range q.choices {
+		fmt.Fprintf(w, "  %v) %s\n", i+1, c)
+	}
+	fmt.Fprintf(w, "%s: ", q.names)
+}
+
+func (b *Parser) readAnswer(t terminal, hidden bool) (string, error) {
+	if hidden {
+		v, err := t.ReadPassword()
+		fmt.Fprintln(b.sh.Stdout())
+		return v, err
+	}
+	return readLine(b.sh.Stdin())
+}
+
+// readLine reads one byte at a time so that nothing following the
+// line is consumed from r.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	c := make([]byte, 1)
+	for {
+		n, err := r.Read(c)
+		if n == 1 && c[0] == '\n' {
+			break
+		}
+		line = append(line, c[:n]...)
+		if err != nil {
+			return string(line), eofIfEmpty(line, err)
+		}
+	}
+	return strings.TrimRight(string(line), "\r"), nil
+}
+
+func eofIfEmpty(line []byte, err error) error {
+	if len(line) > 0 {
+		return nil
+	}
+	return err
+}
+
+// choice returns the choice numbered by answer, or answer as is.
+func (q *question) choice(answer string) string {
+	i, err := strconv.Atoi(answer)
+	if err != nil || i < 1 || i > len(q.choices) {
+		return answer
+	}
+	return q.choices[i-1]
+}
@@ -0,0 +1,74 @@
+package cmdline
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gregoryv/cmdline/clitest"
+)
+
+func TestParser_PromptMissing(t *testing.T) {
+	sh := clitest.NewShell(t, "login")
+	sh.Terminal = true
+	sh.In.WriteString("john\n2\nsecret\nfile.txt\n")
+
+	cli := NewParser()
+	cli.SetShell(sh)
+	cli.PromptMissing()
+	var (
+		user = cli.Option("-u, --username", "Your name").Required().
+			String("")
+		role = cli.Option("-r, --role").Required().
+			Enum("guest", "guest", "admin")
+		pass = cli.Option("-p, --password", "hidden").Required().
+			String("")
+		file = cli.NamedArg("FILE").String("")
+	)
+	if err := cli.Error(); err != nil {
+		t.Fatal(err)
+	}
+	got := []string{user, role, pass, file}
+	exp := []string{"john", "admin", "secret", "file.txt"}
+	for i := range exp {
+		if got[i] != exp[i] {
+			t.Errorf("got %q, expected %q", got[i], exp[i])
+		}
+	}
+	assertContains(t, sh.Out.String(),
+		"Your name\n-u, --username: ", "  2) admin\n", "FILE: ",
+	)
+}
+
+func TestParser_PromptMissing_notTerminal(t *testing.T) {
+	sh := clitest.NewShell(t, "login")
+	sh.In.WriteString("john\n")
+
+	cli := NewParser()
+	cli.SetShell(sh)
+	cli.PromptMissing()
+	cli.Option("-u, --username").Required().String("")
+
+	var e *MissingOption
+	if err := cli.Error(); !errors.As(err, &e) {
+		t.Fatal("expected MissingOption, got", err)
+	}
+	if sh.Out.Len() > 0 {
+		t.Error("prompted", sh.Out.String())
+	}
+}
+
+func TestParser_PromptMissing_given(t *testing.T) {
+	sh := clitest.NewShell(t, "login", "-u", "eve")
+	sh.Terminal = true
+
+	cli := NewParser()
+	cli.SetShell(sh)
+	cli.PromptMissing()
+	got := cli.Option("-u, --username").Required().String("")
+	if got != "eve" {
+		t.Error(got)
+	}
+	if sh.Out.Len() > 0 {
+		t.Error("prompted", sh.Out.String())
+	}
+}
@@ -0,0 +1,237 @@
+package cmdline
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// NewREPL returns a read-eval-print loop where each line read from
+// the shells stdin is split into arguments, see Split, and given to
+// the command fn with a parser using the shell environment.
+//
+// Completion also calls fn, with output discarded, to find the
+// defined options and group items. Basic.Parse then stops fn, so the
+// command must call Parse before acting on options.
+func NewREPL(sh Shell, fn func(*Basic)) *REPL {
+	return &REPL{
+		Prompt:  "> ",
+		sh:      sh,
+		run:     fn,
+		history: make([]string, 0),
+	}
+}
+
+// REPL runs a command repeatedly, see NewREPL.
+type REPL struct {
+	Prompt string // written before reading each line
+
+	sh      Shell
+	run     func(*Basic)
+	history []string
+}
+
+// Run reads lines until end of input or the line exit. Builtin
+// commands are
+//
+//	help      show usage of the command
+//	history   list previous lines
+//	exit      stop reading lines
+//
+// A line ending with a tab, e.g. typed as hel<Tab><Enter>, lists the
+// completions of the line, see Complete.
+func (r *REPL) Run() error {
+	s := bufio.NewScanner(r.sh.Stdin())
+	fmt.Fprint(r.sh.Stdout(), r.Prompt)
+	for s.Scan() && r.eval(s.Text()) {
+		fmt.Fprint(r.sh.Stdout(), r.Prompt)
+	}
+	return s.Err()
+}
+
+// History returns previous lines, excluding builtins.
+func (r *REPL) History() []string {
+	return r.history
+}
+
+// eval returns false if the loop should stop
+func (r *REPL) eval(line string) bool {
+	if strings.HasSuffix(line, "\t") {
+		r.listCompletions(strings.TrimSuffix(line, "\t"))
+		return true
+	}
+	return r.evalArgs(line)
+}
+
+func (r *REPL) listCompletions(line string) {
+	fmt.Fprintln(r.sh.Stdout(), strings.Join(r.Complete(line), " "))
+}
+
+func (r *REPL) evalArgs(line string) bool {
+	args, err := Split(line)
+	switch {
+	case err != nil:
+		fmt.Fprintln(r.sh.Stderr(), err)
+	case len(args) == 0:
+	case args[0] == "exit":
+		return false
+	default:
+		r.dispatch(line, args)
+	}
+	return true
+}
+
+func (r *REPL) dispatch(line string, args []string) {
+	switch args[0] {
+	case "history":
+		for i, h := range r.history {
+			fmt.Fprintf(r.sh.Stdout(), "%4v  %s\n", i+1, h)
+		}
+		return
+	case "help":
+		args = []string{"-h"}
+	default:
+		r.history = append(r.history, line)
+	}
+	r.exec(r.shell(args, false), false)
+}
+
+// shell returns a shell for one command, quiet discards output.
+func (r *REPL) shell(args []string, quiet bool) *replShell {
+	var cmd string // empty if the shell has no arguments
+	if all := r.sh.Args(); len(all) > 0 {
+		cmd = all[0]
+	}
+	return &replShell{
+		Shell: r.sh,
+		args:  append([]string{cmd}, args...),
+		quiet: quiet,
+	}
+}
+
+// exec runs the command, returning the parser used and the exit
+// code. With defineOnly the command stops in Basic.Parse.
+func (r *REPL) exec(sh *replShell, defineOnly bool) (b *Basic, code int) {
+	b = NewBasicParser(WithShell(sh))
+	b.defineOnly = defineOnly
+	defer func() {
+		if e := recover(); e != nil {
+			code = sh.exited(e)
+		}
+	}()
+	r.run(b)
+	return b, 0
+}
+
+// Complete returns possible completions of the last word in line
+// using options and group items of the command. If a group item is
+// given, its extra options are included. The command is only run up
+// to Basic.Parse, see NewREPL.
+func (r *REPL) Complete(line string) []string {
+	words := strings.Fields(line)
+	var last string
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		last = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	b, _ := r.exec(r.shell(nil, true), true)
+	res := make([]string, 0)
+	for _, name := range completions(b.Parser, words) {
+		if strings.HasPrefix(name, last) {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// completions returns names of options and group items of p. If an
+// item is found in words, its extra options are used instead.
+func completions(p *Parser, words []string) []string {
+	names := optionNames(p.options)
+	if len(words) == 0 {
+		names = append(names, "exit", "help", "history")
+	}
+	for _, grp := range p.groups {
+		names = append(names, groupCompletions(grp, words)...)
+	}
+	return names
+}
+
+func groupCompletions(grp *Group, words []string) []string {
+	for _, w := range words {
+		if item, found := grp.find(w); found {
+			extra := grp.parser([]string{item.Name})
+			item.Load(extra)
+			return optionNames(extra.options)
+		}
+	}
+	names := make([]string, len(grp.items))
+	for i, item := range grp.items {
+		names[i] = item.Name
+	}
+	return names
+}
+
+// optionNames returns all names of the given options, excluding
+// environment variables.
+func optionNames(options []*Option) []string {
+	names := make([]string, 0, len(options))
+	for _, opt := range documented(options) {
+		for _, name := range opt.argNames() {
+			if isOption(name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// replShell runs one command, exit is intercepted so the loop can
+// continue.
+type replShell struct {
+	Shell
+	args  []string
+	quiet bool
+}
+
+func (s *replShell) Args() []string { return s.args }
+
+func (s *replShell) Stdout() io.Writer {
+	if s.quiet {
+		return io.Discard
+	}
+	return s.Shell.Stdout()
+}
+
+func (s *replShell) Stderr() io.Writer {
+	if s.quiet {
+		return io.Discard
+	}
+	return s.Shell.Stderr()
+}
+
+// Exit stops the command by panicing, see exited.
+func (s *replShell) Exit(code int) {
+	panic(replExit(code))
+}
+
+// Fatal writes v to stderr and exits with 1
+func (s *replShell) Fatal(v ...interface{}) {
+	fmt.Fprintln(s.Stderr(), v...)
+	s.Exit(1)
+}
+
+// exited returns the exit code of recovered value e, other panics
+// are propagated.
+func (s *replShell) exited(e interface{}) int {
+	code, ok := e.(replExit)
+	if !ok {
+		panic(e)
+	}
+	return int(code)
+}
+
+type replExit int
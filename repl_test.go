@@ -0,0 +1,135 @@
+package cmdline
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gregoryv/cmdline/clitest"
+)
+
+func speak(cli *Basic) {
+	var (
+		loud    = cli.Flag("-l, --loud")
+		phrases = cli.Group("Phrases", "PHRASE")
+		_       = phrases.New("hello", func(p *Parser) interface{} {
+			return p.Option("-t, --to, $TO").String("stranger")
+		})
+		_      = phrases.New("bye", "bye")
+		phrase = phrases.Selected()
+	)
+	cli.Parse()
+	msg := fmt.Sprint(phrase)
+	if loud {
+		msg = strings.ToUpper(msg)
+	}
+	fmt.Fprintln(cli.sh.Stdout(), msg)
+}
+
+func TestREPL_Run(t *testing.T) {
+	sh := clitest.NewShell(t, "speak")
+	sh.Env["TO"] = "Eve"
+	sh.In.WriteString(`hello -t John
+hello
+hello -x
+  # comment only
+"unterminated
+bye --loud
+help
+history
+exit
+never
+`)
+	repl := NewREPL(sh, speak)
+	repl.Prompt = ""
+	if err := repl.Run(); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, sh.Out.String(),
+		"John\n", "Eve\n", "BYE\n", "Usage: speak", "   1  hello -t John",
+	)
+	assertContains(t, sh.Err.String(), "Unknown option: -x")
+	if sh.ExitCode != 0 {
+		t.Error("exit called", sh.ExitCode)
+	}
+	exp := []string{"hello -t John", "hello", "hello -x", "bye --loud"}
+	if got := repl.History(); !reflect.DeepEqual(got, exp) {
+		t.Error(got)
+	}
+}
+
+func TestREPL_Complete(t *testing.T) {
+	sh := clitest.NewShell(t, "speak")
+	repl := NewREPL(sh, speak)
+	cases := map[string][]string{
+		"h":        {"hello", "help", "history"},
+		"-":        {"--help", "--loud", "-h", "-l"},
+		"hello -":  {"--help", "--loud", "--to", "-h", "-l", "-t"},
+		"hello --": {"--help", "--loud", "--to"},
+		"bye ":     {"--help", "--loud", "-h", "-l"},
+	}
+	for line, exp := range cases {
+		if got := repl.Complete(line); !reflect.DeepEqual(got, exp) {
+			t.Errorf("%q: got %q, expected %q", line, got, exp)
+		}
+	}
+	if sh.Out.Len() > 0 {
+		t.Error("completion wrote output", sh.Dump())
+	}
+}
+
+func TestREPL_Complete_stops_in_Parse(t *testing.T) {
+	var runs int
+	repl := NewREPL(clitest.NewShell(t, "count"), func(cli *Basic) {
+		cli.Flag("-v")
+		cli.Parse()
+		runs++
+	})
+	if got := repl.Complete("-"); len(got) == 0 {
+		t.Error("no completions")
+	}
+	if runs > 0 {
+		t.Error("command run on completion")
+	}
+}
+
+func TestREPL_Run_without_arguments(t *testing.T) {
+	sh := clitest.NewShell(t)
+	sh.In.WriteString("-v\n-\t\n")
+	repl := NewREPL(noArgs{sh}, func(cli *Basic) {
+		cli.Flag("-v")
+		cli.Parse()
+	})
+	if err := repl.Run(); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, sh.Out.String(), "-v")
+}
+
+type noArgs struct{ *clitest.ShellT }
+
+func (noArgs) Args() []string { return nil }
+
+func TestREPL_Run_completion(t *testing.T) {
+	sh := clitest.NewShell(t, "speak")
+	sh.In.WriteString("hello --\t\n")
+	repl := NewREPL(sh, speak)
+	repl.Prompt = ""
+	repl.Run()
+	if got := sh.Out.String(); got != "--help --loud --to\n" {
+		t.Errorf("got %q", got)
+	}
+	if len(repl.History()) > 0 {
+		t.Error("completion added to history")
+	}
+}
+
+func assertContains(t *testing.T, got string, exp ...string) {
+	t.Helper()
+	for _, e := range exp {
+		if !strings.Contains(got, e) {
+			t.Errorf("missing %q in\n%s", e, got)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package cmdline
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// maxResponseDepth limits nested response files
+const maxResponseDepth = 10
+
+// expandArgs replaces each @path argument with arguments read from
+// the file, see Split for the format. Arguments naming files that do
+// not exist are kept as is, arguments after -- are not expanded.
+func expandArgs(sh Shell, args []string, depth int) ([]string, error) {
+	res := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			return append(res, args[i:]...), nil
+		}
+		more, err := expandArg(sh, arg, depth)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, more...)
+	}
+	return res, nil
+}
+
+func expandArg(sh Shell, arg string, depth int) ([]string, error) {
+	if len(arg) < 2 || arg[0] != '@' {
+		return []string{arg}, nil
+	}
+	return readResponseFile(sh, arg, depth)
+}
+
+// readResponseFile returns arguments read from file named by arg,
+// e.g. @args.txt, relative to the shell working directory.
+func readResponseFile(sh Shell, arg string, depth int) ([]string, error) {
+	if depth == maxResponseDepth {
+		return nil, fmt.Errorf("%s: too many nested response files", arg)
+	}
+	data, err := fs.ReadFile(sh.FS(), FSPath(sh, arg[1:]))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{arg}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	args, err := Split(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", arg, err)
+	}
+	return expandArgs(sh, args, depth+1)
+}
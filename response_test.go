@@ -0,0 +1,85 @@
+package cmdline
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gregoryv/cmdline/clitest"
+)
+
+func Test_response_files(t *testing.T) {
+	sh := clitest.NewShell(t, "cmd", "-a", "@args.txt", "last", "--", "@x")
+	writeFile(t, sh, "args.txt", `
+# options
+-b "x y" @more.txt
+`)
+	writeFile(t, sh, "more.txt", "-c 'z'")
+
+	cli := NewParser(WithShell(sh), WithResponseFiles())
+	cli.Flag("-a")
+	b := cli.Option("-b").String("")
+	c := cli.Option("-c").String("")
+	if !cli.Ok() {
+		t.Fatal(cli.Error())
+	}
+	if b != "x y" || c != "z" {
+		t.Error("got", b, c)
+	}
+	if got := cli.Args(); !reflect.DeepEqual(got, []string{"last", "@x"}) {
+		t.Error("got", got)
+	}
+}
+
+func Test_response_files_disabled(t *testing.T) {
+	sh := clitest.NewShell(t, "cmd", "@args.txt")
+	writeFile(t, sh, "args.txt", "-v")
+	cli := NewParser(WithShell(sh))
+	if got := cli.NamedArg("FILE").String(""); got != "@args.txt" {
+		t.Error("got", got)
+	}
+}
+
+func Test_response_file_missing_is_argument(t *testing.T) {
+	cli := NewParser(WithArgs("cmd", "@john"), WithResponseFiles())
+	if got := cli.NamedArg("NAME").String(""); got != "@john" {
+		t.Error("got", got)
+	}
+}
+
+func Test_response_file_errors(t *testing.T) {
+	cases := map[string]string{
+		"quote.txt": `-a "x`,
+		"loop.txt":  "@loop.txt",
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			sh := clitest.NewShell(t, "cmd", "@"+name)
+			writeFile(t, sh, name, content)
+
+			cli := NewParser(WithShell(sh), WithResponseFiles())
+			err := cli.Error()
+			if err == nil || !strings.Contains(err.Error(), name) {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_response_file_after_Chdir(t *testing.T) {
+	sh := clitest.NewShell(t, "cmd", "@args.txt")
+	writeFile(t, sh, "sub/args.txt", "-v")
+	sh.Chdir("sub")
+	cli := NewParser(WithShell(sh), WithResponseFiles())
+	if !cli.Flag("-v") {
+		t.Error("response file not read from working directory", cli.Args())
+	}
+}
+
+func writeFile(t *testing.T, sh *clitest.ShellT, name, content string) {
+	t.Helper()
+	err := sh.WriteFile(name, []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
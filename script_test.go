@@ -0,0 +1,13 @@
+package cmdline
+
+import (
+	"testing"
+
+	"github.com/gregoryv/cmdline/clitest"
+)
+
+func TestScripts(t *testing.T) {
+	clitest.RunScripts(t, "testdata/scripts/*.txt", func(sh Shell) {
+		speak(NewBasicParser(WithShell(sh)))
+	})
+}
@@ -0,0 +1,178 @@
+package cmdline
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// mask replaces hidden and secret values in usage and errors
+const mask = "********"
+
+// Secret returns the option value as a secret, e.g. a password or
+// token. For an option named --token, $TOKEN the value is read from
+// the first of
+//
+//	--token VALUE      as given, - reads the value from stdin
+//	--token-file FILE  content of the file
+//	$TOKEN             the environment variable
+//	$TOKEN_FILE        content of the file named in the environment
+//
+// Trailing newlines of the value are removed. The value is never
+// shown in usage nor errors. If the shell implements
+//
+//	Mask(string)
+//
+// as clitest.ShellT does, the value is given to it for masking.
+func (opt *Option) Secret() *Secret {
+	opt.hidden = true
+	opt.setDefault("")
+	sources := []func() ([]byte, bool, error){
+		opt.secretGiven, opt.secretFile,
+		opt.secretEnv, opt.secretEnvFile,
+	}
+	for _, source := range sources {
+		b, found, err := source()
+		if found || err != nil {
+			opt.err = err
+			return opt.newSecret(b)
+		}
+	}
+	return &Secret{}
+}
+
+func (opt *Option) newSecret(b []byte) *Secret {
+	b = trimNewline(b)
+	opt.given = len(b) > 0
+	if m, ok := opt.shell().(interface{ Mask(string) }); ok && opt.given {
+		m.Mask(string(b))
+	}
+	return &Secret{b: b}
+}
+
+func (opt *Option) secretGiven() ([]byte, bool, error) {
+	v, found, err := opt.argValue(opt.matchNames())
+	if !found || err != nil || v != "-" {
+		return []byte(v), found, err
+	}
+	b, err := io.ReadAll(opt.shell().Stdin())
+	return b, true, err
+}
+
+func (opt *Option) secretFile() ([]byte, bool, error) {
+	file, found, err := opt.argValue(opt.fileNames())
+	if !found || err != nil {
+		return nil, found, err
+	}
+	return opt.readSecret(file)
+}
+
+func (opt *Option) secretEnv() ([]byte, bool, error) {
+	name := opt.envName()
+	if name == "" {
+		return nil, false, nil
+	}
+	v := opt.envMap(name)
+	return []byte(v), v != "", nil
+}
+
+func (opt *Option) secretEnvFile() ([]byte, bool, error) {
+	name := opt.envName()
+	if name == "" || opt.envMap(name+"_FILE") == "" {
+		return nil, false, nil
+	}
+	return opt.readSecret(opt.envMap(name + "_FILE"))
+}
+
+func (opt *Option) readSecret(file string) ([]byte, bool, error) {
+	sh := opt.shell()
+	b, err := fs.ReadFile(sh.FS(), FSPath(sh, file))
+	if err != nil {
+		// the file name is not secret
+		err = &InvalidValue{Option: opt.names, Value: file, Err: err}
+	}
+	return b, true, err
+}
+
+// fileNames returns long option names with suffix -file, e.g.
+// --token-file.
+func (opt *Option) fileNames() []string {
+	names := make([]string, 0)
+	for _, name := range opt.argNames() {
+		if strings.HasPrefix(name, "--") {
+			names = append(names, name+"-file")
+		}
+	}
+	return names
+}
+
+// envName returns the environment variable name without $ or empty
+// if none.
+func (opt *Option) envName() string {
+	names := opt.argNames()
+	last := names[len(names)-1]
+	if last == "" || last[0] != '$' {
+		return ""
+	}
+	return last[1:]
+}
+
+// shell returns the shell of the parser or DefaultShell if created
+// with NewOption.
+func (opt *Option) shell() Shell {
+	if opt.p == nil {
+		return DefaultShell
+	}
+	return opt.p.sh
+}
+
+func trimNewline(b []byte) []byte {
+	b = bytes.TrimSuffix(b, []byte("\n"))
+	return bytes.TrimSuffix(b, []byte("\r"))
+}
+
+// maskError returns err with v masked in its message.
+func maskError(err error, v string) error {
+	if v == "" {
+		return err
+	}
+	msg := strings.ReplaceAll(err.Error(), v, mask)
+	return &maskedError{err: err, msg: msg}
+}
+
+type maskedError struct {
+	err error
+	msg string
+}
+
+func (e *maskedError) Error() string { return e.msg }
+func (e *maskedError) Unwrap() error { return e.err }
+
+// Secret holds a sensitive value. Call Zero when done with it.
+type Secret struct {
+	b []byte
+}
+
+// Bytes returns the secret value. The returned slice is zeroed by
+// Zero.
+func (s *Secret) Bytes() []byte { return s.b }
+
+// Empty returns true if no value was given.
+func (s *Secret) Empty() bool { return len(s.b) == 0 }
+
+// String returns the value masked, so it's not printed by mistake.
+func (s *Secret) String() string {
+	if s.Empty() {
+		return ""
+	}
+	return mask
+}
+
+// Zero overwrites the value with zeros and empties the secret.
+func (s *Secret) Zero() {
+	for i := range s.b {
+		s.b[i] = 0
+	}
+	s.b = s.b[:0]
+}
@@ -0,0 +1,94 @@
+package cmdline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gregoryv/cmdline/clitest"
+)
+
+func TestOption_Secret(t *testing.T) {
+	sh := clitest.NewShell(t, "login")
+	writeFile(t, sh, "token.txt", "fromfile\n")
+	sh.In.WriteString("fromstdin\n")
+	sh.Env["TOKEN"] = "fromenv"
+
+	cases := []struct {
+		args []string
+		exp  string
+	}{
+		{[]string{"--token", "given"}, "given"},
+		{[]string{"--token=-"}, "fromstdin"},
+		{[]string{"--token-file", "token.txt"}, "fromfile"},
+		{[]string{}, "fromenv"},
+	}
+	for _, c := range cases {
+		args := append([]string{"login"}, c.args...)
+		cli := NewParser(WithShell(sh), WithArgs(args...))
+		s := cli.Option("--token, $TOKEN").Secret()
+		if got := string(s.Bytes()); got != c.exp {
+			t.Errorf("%v: got %q, expected %q", c.args, got, c.exp)
+		}
+		if err := cli.Error(); err != nil {
+			t.Error(c.args, err)
+		}
+	}
+
+}
+
+func TestOption_Secret_envFile(t *testing.T) {
+	sh := clitest.NewShell(t, "login")
+	writeFile(t, sh, "key.txt", "fromfile\n")
+	sh.Env["KEY_FILE"] = "key.txt"
+
+	cli := NewParser(WithShell(sh))
+	s := cli.Option("-k, --key, $KEY").Secret()
+	if got := string(s.Bytes()); got != "fromfile" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestOption_Secret_missingFile(t *testing.T) {
+	sh := clitest.NewShell(t)
+	cli := NewParser(
+		WithShell(sh), WithArgs("login", "--token-file", "no-such-file"),
+	)
+	cli.Option("--token").Secret()
+	var e *InvalidValue
+	if err := cli.Error(); !errors.As(err, &e) {
+		t.Error("expected InvalidValue, got", err)
+	}
+}
+
+func TestSecret_masked(t *testing.T) {
+	sh := clitest.NewShell(t, "login", "--token", "s3cret", "--pin", "12x")
+	cli := NewParser(WithShell(sh))
+	cli.ReportAll()
+	s := cli.Option("--token").Secret()
+	cli.Option("--pin", "hidden").Int(0)
+
+	fmt.Fprintln(sh.Stdout(), s, cli.Error())
+	cli.Usage().WriteTo(sh.Stdout())
+	if got := sh.Dump(); strings.Contains(got, "s3cret") {
+		t.Error(got)
+	}
+	if got := cli.Error().Error(); strings.Contains(got, "12x") {
+		t.Error(got)
+	}
+	if got := cli.String(); strings.Contains(got, "s3cret") ||
+		strings.Contains(got, "12x") {
+		t.Error(got)
+	}
+}
+
+func TestSecret_Zero(t *testing.T) {
+	cli := NewParser(WithArgs("login", "--token", "s3cret"))
+	s := cli.Option("--token").Secret()
+	b := s.Bytes()
+	s.Zero()
+	if string(b) != "\x00\x00\x00\x00\x00\x00" || !s.Empty() {
+		t.Errorf("%q", b)
+	}
+}
@@ -1,9 +1,14 @@
 package cmdline
 
 import (
+	"context"
 	"io"
+	"io/fs"
 	"log"
 	"os"
+	"os/signal"
+
+	"golang.org/x/term"
 )
 
 // Shell defines a command line execution context.
@@ -11,6 +16,16 @@ type Shell interface {
 	Getenv(string) string
 	Args() []string
 	Getwd() (string, error)
+
+	// FS returns the file system of the shell rooted at /, use
+	// FSPath for names relative to the working directory and
+	// WriteFile to write files.
+	FS() fs.FS
+
+	// Notify and Stop work as signal.Notify and signal.Stop, see
+	// NotifyContext.
+	Notify(c chan<- os.Signal, sig ...os.Signal)
+	Stop(c chan<- os.Signal)
 	Stdin() io.Reader
 	Stdout() io.Writer
 	Stderr() io.Writer
@@ -39,6 +54,17 @@ func (s *ShellOS) Args() []string { return os.Args }
 // Getwd returns os.Getwd
 func (s *ShellOS) Getwd() (string, error) { return os.Getwd() }
 
+// FS returns a file system using package os
+func (s *ShellOS) FS() fs.FS { return osFS{} }
+
+// Notify calls signal.Notify
+func (s *ShellOS) Notify(c chan<- os.Signal, sig ...os.Signal) {
+	signal.Notify(c, sig...)
+}
+
+// Stop calls signal.Stop
+func (s *ShellOS) Stop(c chan<- os.Signal) { signal.Stop(c) }
+
 // Stdin returns os.Stdin
 func (s *ShellOS) Stdin() io.Reader { return os.Stdin }
 
@@ -54,3 +80,38 @@ func (s *ShellOS) Fatal(v ...interface{}) {
 	log.Println(v...)
 	s.exit(1)
 }
+
+// IsTerminal returns true if os.Stdin is a terminal
+func (s *ShellOS) IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// ReadPassword reads a line from os.Stdin without echo
+func (s *ShellOS) ReadPassword() (string, error) {
+	v, err := term.ReadPassword(int(os.Stdin.Fd()))
+	return string(v), err
+}
+
+// NotifyContext returns a copy of the parent context that is canceled
+// when one of the given signals is received by the shell, or when
+// the returned stop function is called. Same as signal.NotifyContext
+// but signals are received through the shell, e.g.
+//
+//	ctx, stop := cmdline.NotifyContext(ctx, sh, os.Interrupt)
+//	defer stop()
+func NotifyContext(
+	parent context.Context, sh Shell, sig ...os.Signal,
+) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	c := make(chan os.Signal, 1)
+	sh.Notify(c, sig...)
+	go func() {
+		select {
+		case <-c:
+			cancel()
+		case <-ctx.Done():
+		}
+		sh.Stop(c)
+	}()
+	return ctx, cancel
+}
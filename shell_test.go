@@ -1,7 +1,16 @@
 package cmdline
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
+
+	"github.com/gregoryv/cmdline/clitest"
 )
 
 func TestShellOS(t *testing.T) {
@@ -45,3 +54,50 @@ func TestShellOS_exits(t *testing.T) {
 	sh.Fatal()
 	sh.Exit(1)
 }
+
+func TestShellOS_FS(t *testing.T) {
+	sh := NewShellOS()
+	fsys := sh.FS()
+	name := FSPath(sh, filepath.Join(t.TempDir(), "a.txt"))
+	if err := WriteFile(fsys, name, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := fs.ReadFile(fsys, name); string(data) != "hello" {
+		t.Errorf("%q", data)
+	}
+	if err := WriteFile(fstest.MapFS{}, "a.txt", nil, 0644); err == nil {
+		t.Error("expected error on read only file system")
+	}
+	if _, err := fsys.Open("/etc"); !errors.Is(err, fs.ErrInvalid) {
+		t.Error("absolute name:", err)
+	}
+}
+
+func TestFSPath(t *testing.T) {
+	sh := clitest.NewShell(t, "cmd")
+	sh.Chdir("/home/john")
+	cases := map[string]string{
+		"a.txt":         "home/john/a.txt",
+		"../eve/b.txt":  "home/eve/b.txt",
+		"/etc/hosts":    "etc/hosts",
+		"/":             ".",
+		"./x/../y.conf": "home/john/y.conf",
+	}
+	for name, exp := range cases {
+		if got := FSPath(sh, name); got != exp {
+			t.Errorf("%s: got %q, expected %q", name, got, exp)
+		}
+	}
+}
+
+func TestNotifyContext(t *testing.T) {
+	sh := clitest.NewShell(t, "serve")
+	sh.SendSignal(os.Interrupt) // Ctrl-C
+	res := clitest.RunShell(sh, func(sh Shell) {
+		ctx, stop := NotifyContext(context.Background(), sh, os.Interrupt)
+		defer stop()
+		<-ctx.Done()
+		fmt.Fprintln(sh.Stdout(), "graceful shutdown")
+	})
+	res.Assert(t).StdoutContains("graceful shutdown")
+}
@@ -0,0 +1,12 @@
+package cmdline
+
+import "github.com/gregoryv/cmdline/internal/shlex"
+
+// Split splits s into words the way a POSIX shell does, without any
+// expansions. Words are separated by whitespace, quoted with single
+// or double quotes and backslash escapes the next character. Within
+// double quotes backslash only escapes $ ` " \ and newline. Words
+// starting with # begin a comment ending at the next newline.
+func Split(s string) ([]string, error) {
+	return shlex.Split(s)
+}
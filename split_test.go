@@ -0,0 +1,52 @@
+package cmdline
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	cases := []struct {
+		in  string
+		exp []string
+	}{
+		{"", []string{}},
+		{"  a  b\tc\nd ", []string{"a", "b", "c", "d"}},
+		{`'a b' "c d"`, []string{"a b", "c d"}},
+		{`'' ""`, []string{"", ""}},
+		{`a'b'"c"d`, []string{"abcd"}},
+		{`'a\b' "a\b"`, []string{`a\b`, `a\b`}},
+		{`"\$ \" \\ \x"`, []string{`$ " \ \x`}},
+		{`a\ b \'c`, []string{"a b", "'c"}},
+		{"a \\\nb", []string{"a", "b"}},
+		{"# comment\na#b # c\nd", []string{"a#b", "d"}},
+		{`'#' "#"`, []string{"#", "#"}},
+		{"-name=\"x y\"", []string{"-name=x y"}},
+	}
+	for _, c := range cases {
+		got, err := Split(c.in)
+		if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.exp) {
+			t.Errorf("%q: got %q, expected %q", c.in, got, c.exp)
+		}
+	}
+}
+
+func TestSplit_errors(t *testing.T) {
+	for _, in := range []string{`'a`, `"a`, `a\`, `"a\"`} {
+		if _, err := Split(in); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
+
+func ExampleSplit() {
+	args, _ := Split(`speak sayHi -t "John Doe" # greet John`)
+	fmt.Printf("%q\n", args)
+	// output:
+	// ["speak" "sayHi" "-t" "John Doe"]
+}
@@ -0,0 +1,121 @@
+package cmdline
+
+import "strings"
+
+// newTokens classifies the given arguments once, so options, named
+// arguments and groups need not scan all arguments repeatedly.
+func newTokens(args []string) *tokens {
+	t := &tokens{
+		list:   make([]*token, len(args)),
+		byName: make(map[string][]int),
+	}
+	var terminated bool
+	for i, arg := range args {
+		tok := classify(arg, terminated)
+		terminated = terminated || tok.kind == terminatorToken
+		t.list[i] = tok
+		if tok.isOption() {
+			t.byName[tok.name] = append(t.byName[tok.name], i)
+		}
+	}
+	return t
+}
+
+type tokens struct {
+	list []*token
+
+	// option name to indexes in list
+	byName map[string][]int
+}
+
+// find returns the index of the first option matching any of the
+// given names.
+func (t *tokens) find(names []string) (int, bool) {
+	at := -1
+	for _, name := range names {
+		idx, found := t.byName[name]
+		if found && (at == -1 || idx[0] < at) {
+			at = idx[0]
+		}
+	}
+	return at, at != -1
+}
+
+// claim marks the i:th token as value of an option, returns false
+// if there is none.
+func (t *tokens) claim(i int) (string, bool) {
+	if i >= len(t.list) || t.list[i].kind == terminatorToken {
+		return "", false
+	}
+	tok := t.list[i]
+	tok.used = true
+	if tok.kind == positionalToken {
+		tok.kind = valueToken
+	}
+	return tok.raw, true
+}
+
+// flagValue returns the value of the flag at index i. With lookahead
+// the following argument is claimed if it's a bool value, e.g. false.
+func (t *tokens) flagValue(i int, lookahead bool) string {
+	tok := t.list[i]
+	tok.used = true
+	if tok.kind == inlineToken {
+		return tok.value
+	}
+	next := i + 1
+	if lookahead && next < len(t.list) && t.list[next].isFlagValue() {
+		v, _ := t.claim(next)
+		return v
+	}
+	return "true"
+}
+
+func classify(arg string, terminated bool) *token {
+	switch {
+	case terminated || !isOption(arg):
+		return &token{raw: arg, kind: positionalToken}
+	case arg == "--":
+		return &token{raw: arg, kind: terminatorToken}
+	}
+	tok := &token{raw: arg, kind: optionToken}
+	name, value, found := strings.Cut(arg, "=")
+	tok.name = name
+	if found {
+		tok.kind = inlineToken
+		tok.value = value
+	}
+	return tok
+}
+
+type token struct {
+	kind  tokenKind
+	raw   string
+	name  string // of options
+	value string // of options with inline value
+	used  bool   // matched by an option or claimed as value
+}
+
+func (t *token) isOption() bool {
+	return t.kind == optionToken || t.kind == inlineToken
+}
+
+// isFlagValue returns true if the token is an unused non empty
+// argument accepted by ParseBool, e.g. - or file.txt is not.
+func (t *token) isFlagValue() bool {
+	if t.kind != positionalToken || t.used || t.raw == "" {
+		return false
+	}
+	_, err := ParseBool(t.raw)
+	return err == nil
+}
+
+type tokenKind int
+
+const (
+	positionalToken tokenKind = iota
+	optionToken               // e.g. -i or --integer
+	inlineToken               // option with value, e.g. -i=1
+	valueToken                // claimed as value by an option
+	terminatorToken           // --
+)
@@ -0,0 +1,80 @@
+package cmdline
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_tokens_classify(t *testing.T) {
+	toks := newTokens([]string{"-a", "-b=x=y", "-", "file", "--", "-c"})
+	exp := []tokenKind{
+		optionToken, inlineToken, positionalToken, positionalToken,
+		terminatorToken, positionalToken,
+	}
+	for i, tok := range toks.list {
+		if tok.kind != exp[i] {
+			t.Errorf("%q: got kind %v, expected %v", tok.raw, tok.kind, exp[i])
+		}
+	}
+	if v := toks.list[1].value; v != "x=y" {
+		t.Error("inline value", v)
+	}
+}
+
+func Test_tokens_find_first(t *testing.T) {
+	toks := newTokens([]string{"--long", "-s", "-s"})
+	i, found := toks.find([]string{"-s", "--long"})
+	if !found || i != 0 {
+		t.Error(i, found)
+	}
+	if _, found := toks.find([]string{"-x"}); found {
+		t.Error("found -x")
+	}
+}
+
+func Test_terminated_options_are_arguments(t *testing.T) {
+	cli := Parse(t, "cmd -v -- -v -x")
+	cli.Flag("-v")
+	if !cli.Ok() {
+		t.Error(cli.Error())
+	}
+	if got := cli.Args(); !reflect.DeepEqual(got, []string{"-v", "-x"}) {
+		t.Error("got", got)
+	}
+}
+
+func Test_flag_value_is_not_an_argument(t *testing.T) {
+	cli := Parse(t, "cmd -v false file -q=true")
+	v := cli.Flag("-v")
+	q := cli.Flag("-q")
+	if v || !q {
+		t.Error("got", v, q)
+	}
+	if got := cli.Args(); !reflect.DeepEqual(got, []string{"file"}) {
+		t.Error("got", got)
+	}
+}
+
+func Test_flag_claims_only_bool_values(t *testing.T) {
+	cases := map[string]bool{
+		"no": true, "T": true, "-": false, "file.txt": false, "": false,
+	}
+	for arg, exp := range cases {
+		toks := newTokens([]string{"-v", arg})
+		toks.flagValue(0, true)
+		if got := toks.list[1].used; got != exp {
+			t.Errorf("%q: claimed %v, expected %v", arg, got, exp)
+		}
+	}
+}
+
+func Test_flag_followed_by_stdin(t *testing.T) {
+	cli := Parse(t, "cat -v -")
+	v := cli.Flag("-v")
+	if !v || !cli.Ok() {
+		t.Error("got", v, cli.Error())
+	}
+	if got := cli.Args(); !reflect.DeepEqual(got, []string{"-"}) {
+		t.Error("got", got)
+	}
+}
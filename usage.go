@@ -14,6 +14,13 @@ type Usage struct {
 
 	preface  strings.Builder
 	examples strings.Builder
+
+	all bool // include undocumented options, see ShowAll
+}
+
+// ShowAll includes undocumented options in the usage.
+func (u *Usage) ShowAll() {
+	u.all = true
 }
 
 // Preface adds lines just before the options section
@@ -44,23 +51,17 @@ func (u *Usage) Example(lines ...string) {
 func (u *Usage) WriteTo(w io.Writer) (int64, error) {
 	p, err := nexus.NewPrinter(w)
 	p.Printf("Usage: %s [OPTIONS]", u.args[0])
-	// Named arguments
-	for _, arg := range u.arguments {
-		if arg.required {
-			p.Printf(" %s", arg.name)
-			continue
-		}
-		p.Printf(" [%s]", arg.name)
-	}
+	u.writeArgumentsTo(p)
 	// Preface
 	p.Print("\n\n")
 	u.writePreface(p)
 	// Options
 	p.Println("Options")
 	u.WriteOptionsTo(p)
-	if len(u.options) > 0 {
+	if len(u.documentedOptions()) > 0 {
 		fmt.Fprintln(w)
 	}
+	u.writeArgumentsDoc(p)
 	u.writeGroups(p)
 	u.writeExamples(p)
 
@@ -76,12 +77,52 @@ func (u *Usage) writeGroups(p *nexus.Printer) {
 	}
 	for _, grp := range u.groups {
 		p.Println(grp.Title())
-		first := grp.Items()[0]
-		writeItem(p, first, u.args, indent, true)
-		for _, item := range grp.Items()[1:] {
-			writeItem(p, item, u.args, indent, false)
+		for i, item := range grp.Items() {
+			u.writeItem(p, grp, item, i == 0)
+		}
+	}
+}
+
+// writeArgumentsTo writes the named arguments synopsis, required
+// ones plain and optional within brackets.
+func (u *Usage) writeArgumentsTo(w io.Writer) {
+	for _, arg := range u.arguments {
+		if arg.required {
+			fmt.Fprintf(w, " %s", arg.name)
+			continue
+		}
+		fmt.Fprintf(w, " [%s]", arg.name)
+	}
+}
+
+// writeArgumentsDoc writes the Arguments section with named
+// arguments having choices or constraints.
+func (u *Usage) writeArgumentsDoc(p *nexus.Printer) {
+	args := u.documentedArguments()
+	if len(args) == 0 {
+		return
+	}
+	if len(u.documentedOptions()) == 0 {
+		p.Println() // after empty Options section
+	}
+	p.Println("Arguments")
+	for _, arg := range args {
+		p.Printf("    %s%s%s%s\n", arg.name,
+			formatDefault(arg.defaultValue, arg.quoteValue),
+			enumOf(arg.enumerated), hintsOf(arg.checks),
+		)
+	}
+	p.Println()
+}
+
+func (u *Usage) documentedArguments() []*NamedArg {
+	res := make([]*NamedArg, 0)
+	for _, arg := range u.arguments {
+		if len(arg.enumerated) > 0 || len(arg.checks) > 0 {
+			res = append(res, arg)
 		}
 	}
+	return res
 }
 
 // WriteOptionsTo writes the Options section to the given writer.
@@ -108,47 +149,101 @@ func (u *Usage) writeExamples(p *nexus.Printer) {
 }
 
 func (u *Usage) writeOptionsTo(w io.Writer, indent string) {
-	for _, opt := range u.options {
+	for _, opt := range u.documentedOptions() {
 		writeOptionTo(w, opt, indent)
 	}
 }
 
-func writeItem(w io.Writer, m *Item, args []string, indent string, dflt bool) {
-	if dflt {
-		fmt.Fprintf(w, "%s%s (default)\n", indent, m.Name)
-	} else {
-		fmt.Fprintf(w, "%s%s\n", indent, m.Name)
+// documentedOptions returns options to include in usage.
+func (u *Usage) documentedOptions() []*Option {
+	if u.all {
+		return u.options
+	}
+	return documented(u.options)
+}
+
+// documented returns options not marked undocumented.
+func documented(options []*Option) []*Option {
+	res := make([]*Option, 0, len(options))
+	for _, opt := range options {
+		if !opt.undocumented {
+			res = append(res, opt)
+		}
 	}
-	extra := NewParser()
-	extra.args = args
+	return res
+}
+
+// writeItem writes the item with its extra options, undocumented
+// ones are included if shown for the parent.
+func (u *Usage) writeItem(w io.Writer, grp *Group, m *Item, dflt bool) {
+	extra := grp.parser([]string{m.Name})
 	m.Load(extra)
-	extra.Usage().writeOptionsTo(w, indent)
+	eu := extra.Usage()
+	eu.all = u.all
+	fmt.Fprintf(w, "%s%s", indent, m.Name)
+	eu.writeArgumentsTo(w)
+	if dflt {
+		fmt.Fprint(w, " (default)")
+	}
+	fmt.Fprintln(w)
+	eu.writeOptionsTo(w, indent)
 }
 
 func writeOptionTo(w io.Writer, opt *Option, indent string) {
-	var def string
-	val := opt.defaultValue
+	fmt.Fprintf(w, "%s    %s%s%s%s\n", indent, opt.names,
+		defaultOf(opt), enumOf(opt.enumerated), hintsOf(opt.checks),
+	)
+	writeDocTo(w, opt, indent)
+}
+
+func defaultOf(opt *Option) string {
+	return formatDefault(maskedDefault(opt), opt.quoteValue)
+}
+
+// formatDefault returns the default value as shown in usage, string
+// values are quoted.
+func formatDefault(val string, quote bool) string {
+	switch {
+	case quote:
+		return fmt.Sprintf(" : %q", val)
+	case val != "":
+		return fmt.Sprintf(" : %v", val)
+	}
+	return ""
+}
+
+// maskedDefault returns the default value, masked if hidden
+func maskedDefault(opt *Option) string {
 	if opt.hidden {
-		val = "********"
+		return mask
 	}
-	if val != "" {
-		def = fmt.Sprintf(" : %v", val)
+	return opt.defaultValue
+}
+
+func enumOf(enumerated []string) string {
+	if len(enumerated) == 0 {
+		return ""
 	}
-	if opt.quoteValue {
-		def = fmt.Sprintf(" : %q", val)
+	return fmt.Sprintf(" %v", enumerated)
+}
+
+// hintsOf returns constraints of the given checks, e.g. " (1..65535)"
+func hintsOf(checks []check) string {
+	if len(checks) == 0 {
+		return ""
 	}
-	var enum string
-	if len(opt.enumerated) > 0 {
-		enum = fmt.Sprintf(" %v", opt.enumerated)
+	hints := make([]string, len(checks))
+	for i, c := range checks {
+		hints[i] = c.hint
 	}
-
-	fmt.Fprintf(w, "%s    %s%s%v\n", indent, opt.names, def, enum)
-	writeDocTo(w, opt, indent)
+	return fmt.Sprintf(" (%s)", strings.Join(hints, ", "))
 }
 
 func writeDocTo(w io.Writer, opt *Option, indent string) {
-	if len(opt.doc) > 0 {
-		for _, line := range opt.doc {
+	doc := opt.doc[:len(opt.doc):len(opt.doc)]
+	lines := append(doc, opt.deprecationDoc()...)
+	if len(lines) > 0 {
+		for _, line := range lines {
 			fmt.Fprintf(w, "%s        %s\n", indent, line)
 		}
 		fmt.Fprintln(w)
@@ -6,12 +6,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/gregoryv/cmdline/clitest"
 	"github.com/gregoryv/golden"
 )
 
 func Test_usage_output_with_extended_docs(t *testing.T) {
 	cli := NewParser()
-	cli.args = []string{"adduser"}
+	cli.setArgs([]string{"adduser"})
 	cli.Flag("-n, --dry-run")
 	_, opt := cli.Option("--uid").IntOpt(0)
 	opt.Doc(
@@ -32,7 +33,7 @@ func Test_usage_output_with_extended_docs(t *testing.T) {
 
 func ExampleParser_usageHiddenPassword() {
 	cli := NewParser()
-	cli.args = []string{"adduser"}
+	cli.setArgs([]string{"adduser"})
 	_, opt := cli.Option("--uid").IntOpt(0)
 	opt.Doc("If not given, one is generated")
 	cli.Option("-p, --password",
@@ -54,7 +55,7 @@ func ExampleParser_usageHiddenPassword() {
 
 func TestUsage_withoutGroups(t *testing.T) {
 	cli := NewParser()
-	cli.args = []string{"adduser"}
+	cli.setArgs([]string{"adduser"})
 	_ = cli.Option("-u, --user-id").String("")
 	_ = cli.Option("-p, --password", "hidden").String("")
 	u := cli.Usage()
@@ -65,3 +66,65 @@ func TestUsage_withoutGroups(t *testing.T) {
 	cli.Usage().WriteTo(&buf)
 	golden.Assert(t, buf.String())
 }
+
+func TestUsage_groupItemArguments(t *testing.T) {
+	cli := NewParser()
+	cli.setArgs([]string{"cp"})
+	actions := cli.Group("Actions", "ACTION")
+	actions.New("copy", func(p *Parser) interface{} {
+		p.NamedArg("SRC").String("")
+		p.NamedArg("DST").String(".")
+		return nil
+	})
+	var buf bytes.Buffer
+	cli.Usage().WriteTo(&buf)
+	got := buf.String()
+	if !strings.Contains(got, "copy SRC [DST] (default)") {
+		t.Error(got)
+	}
+}
+
+func TestBasic_helpAll(t *testing.T) {
+	sh := clitest.NewShell(t, "serve", "--help-all")
+	cli := NewBasicParser()
+	cli.SetShell(sh)
+	cli.Option("-b, --bind").String(":80")
+	trace := cli.Option("--trace").Undocumented().Bool(false)
+	cli.Parse()
+
+	if trace {
+		t.Error("trace should be false")
+	}
+	assertContains(t, sh.Out.String(), "--trace", "--help-all")
+
+	sh = clitest.NewShell(t, "serve", "--trace", "-h")
+	cli = NewBasicParser()
+	cli.SetShell(sh)
+	cli.Option("-b, --bind").String(":80")
+	trace = cli.Option("--trace").Undocumented().Bool(false)
+	cli.Parse()
+
+	if !trace {
+		t.Error("undocumented option not parsed")
+	}
+	if got := sh.Out.String(); strings.Contains(got, "--trace") {
+		t.Error(got)
+	}
+}
+
+func TestBasic_helpAll_groupItems(t *testing.T) {
+	for arg, shown := range map[string]bool{"-h": false, "--help-all": true} {
+		sh := clitest.NewShell(t, "serve", arg)
+		cli := NewBasicParser(WithShell(sh))
+		phrases := cli.Group("Phrases", "PHRASE")
+		phrases.New("hello", func(p *Parser) interface{} {
+			return p.Option("--debug").Undocumented().Bool(false)
+		})
+		phrases.Selected()
+		cli.Parse()
+
+		if got := strings.Contains(sh.Out.String(), "--debug"); got != shown {
+			t.Errorf("%s: --debug shown %v\n%s", arg, got, sh.Out.String())
+		}
+	}
+}
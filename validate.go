@@ -0,0 +1,128 @@
+package cmdline
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+)
+
+// check validates raw values of options and named arguments.
+type check struct {
+	hint string // shown in usage
+	fn   func(string) error
+}
+
+func inRange(min, max float64) check {
+	hint := fmt.Sprintf("%v..%v", min, max)
+	return check{
+		hint: hint,
+		fn: func(v string) error {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return err
+			}
+			if f < min || f > max {
+				return fmt.Errorf("not within %s", hint)
+			}
+			return nil
+		},
+	}
+}
+
+func matching(pattern string) check {
+	re := regexp.MustCompile(pattern)
+	return check{
+		hint: "match " + pattern,
+		fn: func(v string) error {
+			if !re.MatchString(v) {
+				return fmt.Errorf("does not match %s", pattern)
+			}
+			return nil
+		},
+	}
+}
+
+// fileExists checks files in the shell file system, see FSPath
+func fileExists(sh Shell) check {
+	return check{
+		hint: "existing file",
+		fn: func(v string) error {
+			_, err := fs.Stat(sh.FS(), FSPath(sh, v))
+			return err
+		},
+	}
+}
+
+// Range checks that the value is within min and max, inclusive.
+func (opt *Option) Range(min, max float64) *Option {
+	return opt.addCheck(inRange(min, max))
+}
+
+// Match checks that the value matches the given regular
+// expression. Panics if pattern does not compile.
+func (opt *Option) Match(pattern string) *Option {
+	return opt.addCheck(matching(pattern))
+}
+
+// FileExists checks that the value names an existing file.
+func (opt *Option) FileExists() *Option {
+	return opt.addCheck(fileExists(opt.shell()))
+}
+
+// Check adds a custom validation of the value. The hint is shown in
+// usage, e.g. "even numbers".
+func (opt *Option) Check(hint string, fn func(string) error) *Option {
+	return opt.addCheck(check{hint: hint, fn: fn})
+}
+
+func (opt *Option) addCheck(c check) *Option {
+	opt.checks = append(opt.checks, c)
+	opt.validate()
+	return opt
+}
+
+// validate runs all checks on a given value, default values are not
+// checked.
+func (opt *Option) validate() {
+	if !opt.given || opt.err != nil {
+		return
+	}
+	for _, c := range opt.checks {
+		if err := c.fn(opt.value); err != nil {
+			opt.invalid(opt.value, err)
+			return
+		}
+	}
+}
+
+// Range checks that each value is within min and max, inclusive.
+func (b *NamedArg) Range(min, max float64) *NamedArg {
+	return b.addCheck(inRange(min, max))
+}
+
+// Match checks that each value matches the given regular
+// expression. Panics if pattern does not compile.
+func (b *NamedArg) Match(pattern string) *NamedArg {
+	return b.addCheck(matching(pattern))
+}
+
+// FileExists checks that each value names an existing file.
+func (b *NamedArg) FileExists() *NamedArg {
+	return b.addCheck(fileExists(b.p.sh))
+}
+
+// Check adds a custom validation of each value.
+func (b *NamedArg) Check(hint string, fn func(string) error) *NamedArg {
+	return b.addCheck(check{hint: hint, fn: fn})
+}
+
+func (b *NamedArg) addCheck(c check) *NamedArg {
+	b.checks = append(b.checks, c)
+	for _, v := range b.values() {
+		if err := c.fn(v); err != nil {
+			b.invalid(v, err)
+		}
+	}
+	return b
+}
@@ -0,0 +1,92 @@
+package cmdline
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func ExampleOption_Range() {
+	os.Args = []string{"mycmd"} // just for this test
+	cli := NewParser()
+	_, opt := cli.Option("--port").IntOpt(8080)
+	opt.Range(1, 65535)
+	cli.Option("--name").String("john")
+	_, opt = cli.Option("--user").StringOpt("")
+	opt.Match("^[a-z]+$")
+
+	cli.Usage().WriteTo(os.Stdout)
+	// output:
+	// Usage: mycmd [OPTIONS]
+	//
+	// Options
+	//     --port : 8080 (1..65535)
+	//     --name : "john"
+	//     --user : "" (match ^[a-z]+$)
+}
+
+func Test_option_checks(t *testing.T) {
+	even := func(v string) error {
+		i, _ := strconv.Atoi(v)
+		if i%2 != 0 {
+			return fmt.Errorf("odd")
+		}
+		return nil
+	}
+	cases := []struct {
+		args string
+		ok   bool
+		opt  func(*Parser)
+	}{
+		{"cmd --port 80", true, func(p *Parser) {
+			_, opt := p.Option("--port").IntOpt(0)
+			opt.Range(1, 65535)
+		}},
+		{"cmd --port 0", false, func(p *Parser) {
+			_, opt := p.Option("--port").IntOpt(0)
+			opt.Range(1, 65535)
+		}},
+		{"cmd", true, func(p *Parser) {
+			// default values are not checked
+			_, opt := p.Option("--port").IntOpt(0)
+			opt.Range(1, 65535)
+		}},
+		{"cmd --port 70000", false, func(p *Parser) {
+			// checks can be given before
+			p.Option("--port").Range(1, 65535).Int(0)
+		}},
+		{"cmd -u John", false, func(p *Parser) {
+			_, opt := p.Option("-u").StringOpt("")
+			opt.Match("^[a-z]+$")
+		}},
+		{"cmd -n 3", false, func(p *Parser) {
+			_, opt := p.Option("-n").IntOpt(0)
+			opt.Check("even numbers", even)
+		}},
+		{"cmd -f nosuch.txt", false, func(p *Parser) {
+			_, opt := p.Option("-f").StringOpt("")
+			opt.FileExists()
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.args, func(t *testing.T) {
+			cli := Parse(t, c.args)
+			c.opt(cli)
+			if err := cli.Error(); c.ok != (err == nil) {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_named_arg_checks(t *testing.T) {
+	cli := Parse(t, "cmd 80 x")
+	cli.NamedArg("PORT").Range(1, 65535).String("")
+	cli.NamedArg("FILES...").FileExists().Strings()
+	var e *InvalidValue
+	if !errors.As(cli.Error(), &e) || e.Value != "x" {
+		t.Error(cli.Error())
+	}
+}
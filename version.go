@@ -0,0 +1,83 @@
+package cmdline
+
+import (
+	"io"
+	"runtime/debug"
+
+	"github.com/gregoryv/nexus"
+)
+
+// EnableVersion defines the version flag with the given names, e.g.
+// "-V, --version". If given, Parse writes version information to
+// stdout and exits 0. Values not set in info are read from the build
+// information of the binary, see runtime/debug.ReadBuildInfo.
+func (b *Basic) EnableVersion(info VersionInfo, names string) {
+	b.versionInfo = &info
+	b.version = b.Parser.Flag(names)
+}
+
+func (b *Basic) writeVersion() {
+	info := *b.versionInfo
+	if bi, ok := readBuildInfo(); ok {
+		info.fill(bi)
+	}
+	info.Command = b.args[0]
+	info.WriteTo(b.sh.Stdout())
+}
+
+var readBuildInfo = debug.ReadBuildInfo
+
+// VersionInfo describes the build of a command.
+type VersionInfo struct {
+	Command  string // set by Basic to the name of the command
+	Version  string // e.g. v1.2.0
+	Revision string // version control revision
+	Dirty    bool   // true if built with local modifications
+	Time     string // of the revision
+	Go       string // e.g. go1.22.1
+}
+
+// fill sets empty values from the given build information.
+func (v *VersionInfo) fill(bi *debug.BuildInfo) {
+	settings := make(map[string]string)
+	for _, s := range bi.Settings {
+		settings[s.Key] = s.Value
+	}
+	setEmpty(&v.Version, bi.Main.Version)
+	setEmpty(&v.Revision, settings["vcs.revision"])
+	setEmpty(&v.Time, settings["vcs.time"])
+	setEmpty(&v.Go, bi.GoVersion)
+	v.Dirty = v.Dirty || settings["vcs.modified"] == "true"
+}
+
+func setEmpty(dst *string, v string) {
+	if *dst == "" {
+		*dst = v
+	}
+}
+
+// WriteTo writes the command and version on the first line followed
+// by the values given, e.g.
+//
+//	mycmd v1.2.0
+//	revision 1a2b3c4 (dirty)
+//	time 2024-12-21T10:00:00Z
+//	go1.22.1
+func (v *VersionInfo) WriteTo(w io.Writer) (int64, error) {
+	p, err := nexus.NewPrinter(w)
+	p.Println(v.Command, v.Version)
+	if v.Revision != "" {
+		p.Print("revision ", v.Revision)
+		if v.Dirty {
+			p.Print(" (dirty)")
+		}
+		p.Println()
+	}
+	if v.Time != "" {
+		p.Println("time", v.Time)
+	}
+	if v.Go != "" {
+		p.Println(v.Go)
+	}
+	return p.Written, *err
+}
@@ -0,0 +1,73 @@
+package cmdline
+
+import (
+	"os"
+	"runtime/debug"
+	"testing"
+
+	"github.com/gregoryv/cmdline/clitest"
+)
+
+func ExampleVersionInfo_WriteTo() {
+	v := VersionInfo{
+		Command:  "mycmd",
+		Version:  "v1.2.0",
+		Revision: "1a2b3c4",
+		Dirty:    true,
+		Go:       "go1.22.1",
+	}
+	v.WriteTo(os.Stdout)
+	// output:
+	// mycmd v1.2.0
+	// revision 1a2b3c4 (dirty)
+	// go1.22.1
+}
+
+func TestBasic_EnableVersion(t *testing.T) {
+	orig := readBuildInfo
+	t.Cleanup(func() { readBuildInfo = orig })
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			GoVersion: "go1.22.1",
+			Main:      debug.Module{Version: "(devel)"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "1a2b3c4"},
+				{Key: "vcs.time", Value: "2024-12-21T10:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, true
+	}
+	sh := clitest.NewShell(t, "mycmd", "--version")
+	cli := NewBasicParser(WithShell(sh))
+	cli.EnableVersion(VersionInfo{Version: "v1.2.0"}, "-V, --version")
+	cli.Parse()
+
+	if sh.ExitCode != 0 {
+		t.Error("exit code", sh.ExitCode)
+	}
+	assertContains(t, sh.Out.String(),
+		"mycmd v1.2.0\n",
+		"revision 1a2b3c4 (dirty)\n",
+		"time 2024-12-21T10:00:00Z\n",
+		"go1.22.1\n",
+	)
+}
+
+func TestBasic_EnableVersion_names(t *testing.T) {
+	sh := clitest.NewShell(t, "mycmd", "-v")
+	cli := NewBasicParser(WithShell(sh))
+	cli.EnableVersion(VersionInfo{Version: "v1.0.0"}, "-v, --version")
+	cli.Parse()
+
+	assertContains(t, sh.Out.String(), "mycmd v1.0.0\n")
+}
+
+func TestBasic_EnableVersion_afterUsage(t *testing.T) {
+	sh := clitest.NewShell(t, "mycmd", "--version")
+	cli := NewBasicParser(WithShell(sh))
+	cli.Usage()
+	cli.EnableVersion(VersionInfo{Version: "v1.0.0"}, "-V, --version")
+	cli.Parse()
+
+	assertContains(t, sh.Out.String(), "mycmd v1.0.0\n")
+}